@@ -0,0 +1,103 @@
+package exante
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BatchError holds the errors of the failed items of a batch call by ID.
+type BatchError map[string]error
+
+func (e BatchError) Error() string {
+	ids := make([]string, 0, len(e))
+	for id := range e {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	msgs := make([]string, len(ids))
+	for i, id := range ids {
+		msgs[i] = fmt.Sprintf("%s: %v", id, e[id])
+	}
+	return fmt.Sprintf("%d of batch failed: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// SymbolSpecifications fetches the specifications of many symbols using at
+// most concurrency parallel requests. Results are keyed by symbol ID. When
+// some of the requests fail, the successful results are returned along
+// with a BatchError.
+func (c *Client) SymbolSpecifications(ctx context.Context, ids []string, concurrency int) (map[string]*SymbolSpecification, error) {
+	specs := make([]*SymbolSpecification, len(ids))
+	errs := c.WithContext(ctx).batch(ctx, ids, concurrency, func(c *Client, i int) (err error) {
+		specs[i], err = c.SymbolSpecification(ids[i])
+		return err
+	})
+	res := make(map[string]*SymbolSpecification, len(ids))
+	for i, id := range ids {
+		if specs[i] != nil {
+			res[id] = specs[i]
+		}
+	}
+	return res, errs
+}
+
+// SymbolSchedules fetches the schedules of many symbols, see
+// SymbolSpecifications.
+func (c *Client) SymbolSchedules(ctx context.Context, ids []string, concurrency int) (map[string][]SymbolScheduleInterval, error) {
+	schedules := make([][]SymbolScheduleInterval, len(ids))
+	ok := make([]bool, len(ids))
+	errs := c.WithContext(ctx).batch(ctx, ids, concurrency, func(c *Client, i int) (err error) {
+		schedules[i], err = c.SymbolSchedule(ids[i])
+		ok[i] = err == nil
+		return err
+	})
+	res := make(map[string][]SymbolScheduleInterval, len(ids))
+	for i, id := range ids {
+		if ok[i] {
+			res[id] = schedules[i]
+		}
+	}
+	return res, errs
+}
+
+// batch runs fetch for every ID index on a pool of workers. Items not
+// started before ctx is done fail with the context error.
+func (c *Client) batch(ctx context.Context, ids []string, concurrency int, fetch func(c *Client, i int) error) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var (
+		mu   sync.Mutex
+		errs = make(BatchError)
+		wg   sync.WaitGroup
+		jobs = make(chan int)
+	)
+	for w := 0; w < concurrency && w < len(ids); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				err := ctx.Err()
+				if err == nil {
+					err = fetch(c, i)
+				}
+				if err != nil {
+					mu.Lock()
+					errs[ids[i]] = err
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range ids {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package exante
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestSymbolSpecifications(t *testing.T) {
+	defer gock.Off()
+
+	var ids []string
+	for i := 0; i < 20; i++ {
+		id := fmt.Sprintf("S%d.NASDAQ", i)
+		ids = append(ids, id)
+		gock.New(baseUrl).
+			Get("/symbols/" + id + "/specification").
+			Reply(200).
+			Delay(10 * time.Millisecond).
+			BodyString(fmt.Sprintf(`{"leverage":0.2,"lotSize":%d,"units":"Shares"}`, i+1))
+	}
+	ids = append(ids, "MISSING.NASDAQ")
+	gock.New(baseUrl).
+		Get("/symbols/MISSING.NASDAQ/specification").
+		Reply(404).
+		BodyString(`{"message":"not found"}`)
+
+	var (
+		mu               sync.Mutex
+		active, maxAlive int
+	)
+	tracker := func(next Handler) Handler {
+		return func(call *Call) (*http.Response, error) {
+			mu.Lock()
+			active++
+			if active > maxAlive {
+				maxAlive = active
+			}
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				active--
+				mu.Unlock()
+			}()
+			return next(call)
+		}
+	}
+	client := NewClient("", "", "", WithMiddleware(tracker))
+
+	specs, err := client.SymbolSpecifications(context.Background(), ids, 4)
+
+	assert.Equal(t, 20, len(specs))
+	assert.Equal(t, 1.0, specs["S0.NASDAQ"].LotSize)
+	assert.Equal(t, 20.0, specs["S19.NASDAQ"].LotSize)
+	assert.NotContains(t, specs, "MISSING.NASDAQ")
+	batchErr, ok := err.(BatchError)
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(batchErr))
+	assert.EqualError(t, batchErr["MISSING.NASDAQ"], `{"message":"not found"}`)
+	assert.Equal(t, `1 of batch failed: MISSING.NASDAQ: {"message":"not found"}`, err.Error())
+	assert.True(t, maxAlive <= 4, "too many concurrent requests: %d", maxAlive)
+	assert.True(t, maxAlive > 1, "requests were not parallel")
+}
+
+func TestSymbolSchedules(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/symbols/AAPL.NASDAQ/schedule").
+		Reply(200).
+		BodyString(`{"intervals":[{"name":"MainSession","period":{"start":1493040600000,"end":1493064000000}}]}`)
+	gock.New(baseUrl).
+		Get("/symbols/GOOG.NASDAQ/schedule").
+		Reply(200).
+		BodyString(`{"intervals":[]}`)
+
+	schedules, err := NewClient("", "", "").SymbolSchedules(context.Background(),
+		[]string{"AAPL.NASDAQ", "GOOG.NASDAQ"}, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(schedules))
+	assert.Equal(t, SessionMain, schedules["AAPL.NASDAQ"][0].Name)
+	assert.Empty(t, schedules["GOOG.NASDAQ"])
+}
+
+func TestBatchCanceled(t *testing.T) {
+	defer gock.Off()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	specs, err := NewClient("", "", "").SymbolSpecifications(ctx, []string{"A.X", "B.X"}, 2)
+
+	assert.Empty(t, specs)
+	batchErr := err.(BatchError)
+	assert.ErrorIs(t, batchErr["A.X"], context.Canceled)
+	assert.ErrorIs(t, batchErr["B.X"], context.Canceled)
+}
+
+func TestRateLimit(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/types").
+		Persist().
+		Reply(200).
+		BodyString(`[]`)
+
+	client := NewClient("", "", "", WithRateLimit(20, 2))
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		_, err := client.Types()
+		assert.NoError(t, err)
+	}
+	// Two requests from the burst, four more at 50ms intervals
+	elapsed := time.Since(start)
+	assert.True(t, elapsed >= 190*time.Millisecond, "rate limit not applied: %v", elapsed)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	client = NewClient("", "", "", WithRateLimit(1, 1))
+	_, err := client.WithContext(ctx).Types()
+	assert.NoError(t, err)
+	_, err = client.WithContext(ctx).Types()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	for _, rps := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		client = NewClient("", "", "", WithRateLimit(rps, 0))
+		assert.Nil(t, client.limiter, rps)
+		for i := 0; i < 3; i++ {
+			_, err := client.Types()
+			assert.NoError(t, err, rps)
+		}
+	}
+}
@@ -0,0 +1,434 @@
+package exante
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultCacheTTL       = time.Hour
+	DefaultCacheSaveDelay = 5 * time.Second
+)
+
+// Cache keys, also used in the persisted file.
+const (
+	cacheSymbols   = "symbols"
+	cacheExchanges = "exchanges"
+	cacheGroups    = "groups"
+	cacheTypes     = "types"
+
+	// Followed by symbol, exchange, type or group ID
+	cacheSymbol          = "symbol/"
+	cacheSpecification   = "specification/"
+	cacheSchedule        = "schedule/"
+	cacheExchangeSymbols = "exchange/"
+	cacheTypeSymbols     = "type/"
+	cacheGroupSymbols    = "group/"
+)
+
+func init() {
+	gob.Register([]Symbol{})
+	gob.Register([]Exchange{})
+	gob.Register([]Group{})
+	gob.Register([]SymbolType{})
+	// Cache files written before SymbolType existed store types as strings.
+	gob.Register([]string{})
+	gob.Register([]SymbolScheduleInterval{})
+	gob.Register(&Symbol{})
+	gob.Register(&SymbolSpecification{})
+}
+
+type CacheOptions struct {
+	// Time to live of each resource, DefaultCacheTTL when zero. SymbolsTTL
+	// also applies to single symbols and per exchange, type and group lists.
+	SymbolsTTL        time.Duration
+	ExchangesTTL      time.Duration
+	GroupsTTL         time.Duration
+	TypesTTL          time.Duration
+	SchedulesTTL      time.Duration
+	SpecificationsTTL time.Duration
+
+	// Path of the file the cache is persisted to. Persistence is disabled
+	// when empty.
+	Path string
+	// Fetches are written to Path in batches, at most once per SaveDelay,
+	// DefaultCacheSaveDelay when zero. Flush and Close write at once.
+	SaveDelay time.Duration
+}
+
+// Cache keeps the rarely changing reference data of a SymbolSource, usually
+// a Client, in memory and optionally on disk. Concurrent requests for the
+// same resource share a single call. Returned values are shared and must not
+// be modified.
+type Cache struct {
+	source SymbolSource
+	opts   CacheOptions
+	now    func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]cacheEntry
+	inflight  map[string]*cacheCall
+	dirty     bool        // entries changed since the last save
+	saveTimer *time.Timer // pending batched save
+
+	// saveMu serializes file writes. Snapshots are taken while holding it,
+	// so the newest one is always written last.
+	saveMu sync.Mutex
+}
+
+type cacheEntry struct {
+	Fetched time.Time
+	Value   interface{}
+}
+
+type cacheCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// NewCache creates a cache in front of source. If opts.Path points to an
+// existing file, the cache is warmed up from it.
+func NewCache(source SymbolSource, opts CacheOptions) (*Cache, error) {
+	c := &Cache{
+		source:   source,
+		opts:     opts,
+		now:      time.Now,
+		entries:  make(map[string]cacheEntry),
+		inflight: make(map[string]*cacheCall),
+	}
+	if opts.Path != "" {
+		if err := c.load(); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *Cache) Symbols() ([]Symbol, error) {
+	v, err := c.get(cacheSymbols, false)
+	if err != nil {
+		return nil, err
+	}
+	return v.([]Symbol), nil
+}
+
+func (c *Cache) Exchanges() ([]Exchange, error) {
+	v, err := c.get(cacheExchanges, false)
+	if err != nil {
+		return nil, err
+	}
+	return v.([]Exchange), nil
+}
+
+func (c *Cache) Groups() ([]Group, error) {
+	v, err := c.get(cacheGroups, false)
+	if err != nil {
+		return nil, err
+	}
+	return v.([]Group), nil
+}
+
+func (c *Cache) Types() ([]SymbolType, error) {
+	v, err := c.get(cacheTypes, false)
+	if err != nil {
+		return nil, err
+	}
+	return v.([]SymbolType), nil
+}
+
+func (c *Cache) Symbol(id string) (*Symbol, error) {
+	v, err := c.get(cacheSymbol+id, false)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Symbol), nil
+}
+
+func (c *Cache) SymbolSpecification(id string) (*SymbolSpecification, error) {
+	v, err := c.get(cacheSpecification+id, false)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*SymbolSpecification), nil
+}
+
+func (c *Cache) SymbolSchedule(id string) ([]SymbolScheduleInterval, error) {
+	v, err := c.get(cacheSchedule+id, false)
+	if err != nil {
+		return nil, err
+	}
+	return v.([]SymbolScheduleInterval), nil
+}
+
+// Schedule returns the schedule of a symbol, cached per symbol ID.
+func (c *Cache) Schedule(id string) (*Schedule, error) {
+	intervals, err := c.SymbolSchedule(id)
+	if err != nil {
+		return nil, err
+	}
+	return NewSchedule(intervals), nil
+}
+
+func (c *Cache) ExchangeSymbols(id string) ([]Symbol, error) {
+	v, err := c.get(cacheExchangeSymbols+id, false)
+	if err != nil {
+		return nil, err
+	}
+	return v.([]Symbol), nil
+}
+
+func (c *Cache) TypeSymbols(id SymbolType) ([]Symbol, error) {
+	v, err := c.get(cacheTypeSymbols+string(id), false)
+	if err != nil {
+		return nil, err
+	}
+	return v.([]Symbol), nil
+}
+
+func (c *Cache) GroupSymbols(id string) ([]Symbol, error) {
+	v, err := c.get(cacheGroupSymbols+id, false)
+	if err != nil {
+		return nil, err
+	}
+	return v.([]Symbol), nil
+}
+
+// GroupNearestSymbol is not cached, as the nearest contract changes with
+// time rather than with the reference data.
+func (c *Cache) GroupNearestSymbol(id string) (*Symbol, error) {
+	return c.source.GroupNearestSymbol(id)
+}
+
+// Refresh fetches all cached resources again regardless of their age.
+func (c *Cache) Refresh() error {
+	keys := []string{cacheSymbols, cacheExchanges, cacheGroups, cacheTypes}
+	c.mu.Lock()
+	for key := range c.entries {
+		if strings.Contains(key, "/") {
+			keys = append(keys, key)
+		}
+	}
+	c.mu.Unlock()
+	for _, key := range keys {
+		if _, err := c.get(key, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cache) get(key string, force bool) (interface{}, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok && !force && c.now().Sub(e.Fetched) < c.ttl(key) {
+		c.mu.Unlock()
+		return e.Value, nil
+	}
+	if call, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		<-call.done
+		return call.value, call.err
+	}
+	call := &cacheCall{done: make(chan struct{})}
+	c.inflight[key] = call
+	c.mu.Unlock()
+
+	call.value, call.err = c.fetch(key)
+
+	c.mu.Lock()
+	delete(c.inflight, key)
+	if call.err == nil {
+		c.entries[key] = cacheEntry{Fetched: c.now(), Value: call.value}
+		if persisted(key) {
+			c.scheduleSave()
+		}
+	}
+	c.mu.Unlock()
+	close(call.done)
+	return call.value, call.err
+}
+
+// scheduleSave marks the entries changed and starts a batched save unless
+// one is pending. Callers hold mu.
+func (c *Cache) scheduleSave() {
+	if c.opts.Path == "" {
+		return
+	}
+	c.dirty = true
+	if c.saveTimer != nil {
+		return
+	}
+	delay := c.opts.SaveDelay
+	if delay == 0 {
+		delay = DefaultCacheSaveDelay
+	}
+	c.saveTimer = time.AfterFunc(delay, func() {
+		c.mu.Lock()
+		c.saveTimer = nil
+		c.mu.Unlock()
+		// A failed save leaves the cache dirty, so the next Flush or
+		// batch retries it.
+		c.Flush()
+	})
+}
+
+// Flush writes changed entries to the cache file.
+func (c *Cache) Flush() error {
+	if c.opts.Path == "" {
+		return nil
+	}
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+	c.mu.Lock()
+	if !c.dirty {
+		c.mu.Unlock()
+		return nil
+	}
+	snapshot := make(map[string]cacheEntry, len(c.entries))
+	for key, e := range c.entries {
+		if persisted(key) {
+			snapshot[key] = e
+		}
+	}
+	c.dirty = false
+	c.mu.Unlock()
+
+	if err := c.save(snapshot); err != nil {
+		c.mu.Lock()
+		c.dirty = true
+		c.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Close cancels the pending batched save and flushes the cache.
+func (c *Cache) Close() error {
+	c.mu.Lock()
+	if c.saveTimer != nil {
+		c.saveTimer.Stop()
+		c.saveTimer = nil
+	}
+	c.mu.Unlock()
+	return c.Flush()
+}
+
+func (c *Cache) fetch(key string) (interface{}, error) {
+	prefix, id := cacheKey(key)
+	switch prefix {
+	case cacheSymbols:
+		return c.source.Symbols()
+	case cacheExchanges:
+		return c.source.Exchanges()
+	case cacheGroups:
+		return c.source.Groups()
+	case cacheTypes:
+		return c.source.Types()
+	case cacheSymbol:
+		return c.source.Symbol(id)
+	case cacheSpecification:
+		return c.source.SymbolSpecification(id)
+	case cacheSchedule:
+		return c.source.SymbolSchedule(id)
+	case cacheExchangeSymbols:
+		return c.source.ExchangeSymbols(id)
+	case cacheTypeSymbols:
+		return c.source.TypeSymbols(SymbolType(id))
+	default:
+		return c.source.GroupSymbols(id)
+	}
+}
+
+// persisted reports whether an entry is written to the cache file. Single
+// symbols, specifications and per exchange, type or group lists are kept
+// in memory only, as saving the whole file for each of them would cost far
+// more than fetching them again.
+func persisted(key string) bool {
+	switch prefix, _ := cacheKey(key); prefix {
+	case cacheSymbol, cacheSpecification, cacheExchangeSymbols, cacheTypeSymbols, cacheGroupSymbols:
+		return false
+	}
+	return true
+}
+
+// cacheKey splits a key into its prefix and ID. Symbol IDs may contain
+// slashes themselves, so only the first one separates.
+func cacheKey(key string) (prefix, id string) {
+	if i := strings.IndexByte(key, '/'); i >= 0 {
+		return key[:i+1], key[i+1:]
+	}
+	return key, ""
+}
+
+func (c *Cache) ttl(key string) time.Duration {
+	var ttl time.Duration
+	prefix, _ := cacheKey(key)
+	switch prefix {
+	case cacheSymbols, cacheSymbol, cacheExchangeSymbols, cacheTypeSymbols, cacheGroupSymbols:
+		ttl = c.opts.SymbolsTTL
+	case cacheExchanges:
+		ttl = c.opts.ExchangesTTL
+	case cacheGroups:
+		ttl = c.opts.GroupsTTL
+	case cacheTypes:
+		ttl = c.opts.TypesTTL
+	case cacheSpecification:
+		ttl = c.opts.SpecificationsTTL
+	default:
+		ttl = c.opts.SchedulesTTL
+	}
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+	return ttl
+}
+
+func (c *Cache) load() error {
+	f, err := os.Open(c.opts.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	// A file that cannot be decoded, e.g. one written by an incompatible
+	// version, is treated as a cold cache and replaced by the next save.
+	entries := make(map[string]cacheEntry)
+	if err := gob.NewDecoder(f).Decode(&entries); err != nil {
+		return nil
+	}
+	if e, ok := entries[cacheTypes]; ok {
+		if names, ok := e.Value.([]string); ok {
+			types := make([]SymbolType, len(names))
+			for i, name := range names {
+				types[i] = SymbolType(name)
+			}
+			e.Value = types
+			entries[cacheTypes] = e
+		}
+	}
+	c.mu.Lock()
+	c.entries = entries
+	c.mu.Unlock()
+	return nil
+}
+
+// save writes entries to a temporary file first, so that a crash never
+// leaves a truncated cache behind.
+func (c *Cache) save(entries map[string]cacheEntry) error {
+	tmp, err := os.CreateTemp(filepath.Dir(c.opts.Path), filepath.Base(c.opts.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := gob.NewEncoder(tmp).Encode(entries); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.opts.Path)
+}
@@ -0,0 +1,247 @@
+package exante
+
+import (
+	"encoding/gob"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func countingClient(calls *int32) *Client {
+	return NewClient("", "", "", WithMiddleware(func(next Handler) Handler {
+		return func(call *Call) (*http.Response, error) {
+			atomic.AddInt32(calls, 1)
+			return next(call)
+		}
+	}))
+}
+
+func TestCacheTTL(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/exchanges").
+		Times(2).
+		Reply(200).
+		BodyString(`[{"id":"NYSE","name":"NYSE: New York Stock Exchange","country":"US"}]`)
+
+	var calls int32
+	cache, err := NewCache(countingClient(&calls), CacheOptions{ExchangesTTL: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		exchanges, err := cache.Exchanges()
+		if err != nil {
+			t.Error(err)
+		}
+		assert.Equal(t, []Exchange{{ID: "NYSE", Name: "NYSE: New York Stock Exchange", Country: "US"}}, exchanges)
+	}
+	assert.Equal(t, int32(1), calls)
+
+	now = now.Add(time.Minute)
+	_, err = cache.Exchanges()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), calls)
+}
+
+func TestCacheCoalescing(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/types").
+		Reply(200).
+		Delay(50 * time.Millisecond).
+		BodyString(`[{"id": "STOCK"}, {"id": "BOND"}]`)
+
+	var calls int32
+	cache, err := NewCache(countingClient(&calls), CacheOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			types, err := cache.Types()
+			assert.NoError(t, err)
+			assert.Equal(t, []SymbolType{SymbolTypeStock, SymbolTypeBond}, types)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestCachePersistence(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/groups").
+		Reply(200).
+		BodyString(`[{"group":"MA","name":"Mastercard","types":["OPTION"],"exchange":"CBOE"}]`)
+	gock.New(baseUrl).
+		Get("/symbols").
+		Reply(200).
+		BodyString(`[{"id":"6R.CME.M2018","ticker":"6R","type":"FUTURE","mpi":5e-06,"expiration":1529028000000}]`)
+
+	path := filepath.Join(t.TempDir(), "exante.cache")
+	cache, err := NewCache(NewClient("", "", ""), CacheOptions{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups, err := cache.Groups()
+	assert.NoError(t, err)
+	symbols, err := cache.Symbols()
+	assert.NoError(t, err)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "saves are batched")
+	assert.NoError(t, cache.Close())
+
+	var calls int32
+	restored, err := NewCache(countingClient(&calls), CacheOptions{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cachedGroups, err := restored.Groups()
+	assert.NoError(t, err)
+	cachedSymbols, err := restored.Symbols()
+	assert.NoError(t, err)
+
+	assert.Equal(t, int32(0), calls)
+	assert.Equal(t, groups, cachedGroups)
+	assert.Equal(t, symbols[0].ID, cachedSymbols[0].ID)
+	assert.True(t, symbols[0].Expiration.Equal(cachedSymbols[0].Expiration.Time))
+}
+
+func TestCacheBatchedSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exante.cache")
+	cache, err := NewCache(&stubMarketData{}, CacheOptions{Path: path, SaveDelay: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cache.Symbols()
+	assert.NoError(t, err)
+	_, err = cache.Exchanges()
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+	assert.NoError(t, cache.Flush(), "nothing left to write")
+
+	stub := &stubMarketData{}
+	restored, err := NewCache(stub, CacheOptions{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = restored.Symbols()
+	assert.NoError(t, err)
+	_, err = restored.Exchanges()
+	assert.NoError(t, err)
+	assert.Empty(t, stub.calls, "both fetches written by one save")
+}
+
+func TestCacheLegacyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exante.cache")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := map[string]cacheEntry{
+		cacheTypes: {Fetched: time.Now(), Value: []string{"STOCK", "FUTURE"}},
+	}
+	assert.NoError(t, gob.NewEncoder(f).Encode(legacy))
+	assert.NoError(t, f.Close())
+
+	stub := &stubMarketData{}
+	cache, err := NewCache(stub, CacheOptions{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	types, err := cache.Types()
+	assert.NoError(t, err)
+	assert.Equal(t, []SymbolType{SymbolTypeStock, SymbolTypeFuture}, types)
+	assert.Empty(t, stub.calls)
+}
+
+func TestCacheUndecodableFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exante.cache")
+	assert.NoError(t, os.WriteFile(path, []byte("not a gob stream"), 0o600))
+
+	stub := &stubMarketData{}
+	cache, err := NewCache(stub, CacheOptions{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cache.Types()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stub.calls["Types"])
+}
+
+func TestCacheRefresh(t *testing.T) {
+	defer gock.Off()
+
+	for _, endpoint := range []string{"/symbols", "/exchanges", "/groups", "/types"} {
+		gock.New(baseUrl).
+			Get(endpoint).
+			Times(2).
+			Reply(200).
+			BodyString(`[]`)
+	}
+
+	var calls int32
+	cache, err := NewCache(countingClient(&calls), CacheOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cache.Symbols()
+	assert.NoError(t, err)
+	assert.NoError(t, cache.Refresh())
+	_, err = cache.Symbols()
+	assert.NoError(t, err)
+
+	assert.Equal(t, int32(5), calls)
+}
+
+func TestCacheSchedule(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/symbols/AAPL.NASDAQ/schedule").
+		Reply(200).
+		BodyString(`{"intervals":[{"name":"MainSession","period":{"start":1493040600000,"end":1493064000000}}]}`)
+	gock.New(baseUrl).
+		Get("/symbols/GOOG.NASDAQ/schedule").
+		Reply(200).
+		BodyString(`{"intervals":[]}`)
+
+	var calls int32
+	cache, err := NewCache(countingClient(&calls), CacheOptions{SchedulesTTL: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		schedule, err := cache.Schedule("AAPL.NASDAQ")
+		assert.NoError(t, err)
+		assert.True(t, schedule.IsOpen(time.Unix(1493050000, 0)))
+		schedule, err = cache.Schedule("GOOG.NASDAQ")
+		assert.NoError(t, err)
+		assert.False(t, schedule.IsOpen(time.Unix(1493050000, 0)))
+	}
+	assert.Equal(t, int32(2), calls)
+}
@@ -0,0 +1,309 @@
+package exante
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type DayStatus string
+
+const (
+	DayTrading    DayStatus = "trading"
+	DayEarlyClose DayStatus = "early_close"
+	DayHoliday    DayStatus = "holiday" // weekday without trading
+	DayWeekend    DayStatus = "weekend"
+	DayUnknown    DayStatus = "unknown" // not covered by the schedules
+)
+
+type CalendarDay struct {
+	Date   string    `json:"date"` // YYYY-MM-DD in the calendar location
+	Status DayStatus `json:"status"`
+	// Earliest main session start and latest main session end among the
+	// symbols, zero when there is no trading
+	Open  time.Time `json:"open"`
+	Close time.Time `json:"close"`
+}
+
+// MarshalJSON omits Open and Close on days without trading.
+func (d CalendarDay) MarshalJSON() ([]byte, error) {
+	type plain CalendarDay
+	v := struct {
+		plain
+		Open  *time.Time `json:"open,omitempty"`
+		Close *time.Time `json:"close,omitempty"`
+	}{plain: plain(d)}
+	if !d.Open.IsZero() {
+		v.Open = &d.Open
+	}
+	if !d.Close.IsZero() {
+		v.Close = &d.Close
+	}
+	return json.Marshal(v)
+}
+
+// ExchangeCalendar reports trading days, early closes and closures of an
+// exchange, derived from the schedules of its symbols.
+type ExchangeCalendar struct {
+	Exchange string         `json:"exchange"`
+	Location *time.Location `json:"-"`
+	Days     []CalendarDay  `json:"days"`
+	// Most common close time of day in the calendar location
+	RegularClose string `json:"regular_close,omitempty"`
+	// Most common length of the session closing a trading day. Days whose
+	// closing session is shorter are early closes. Unlike close times,
+	// lengths do not move with daylight saving time or the location.
+	RegularSession time.Duration `json:"regular_session,omitempty"`
+	// DTSTAMP of exported iCalendar events
+	Created time.Time `json:"-"`
+}
+
+type CalendarOptions struct {
+	// Location the exchange days are computed in, UTC when nil
+	Location *time.Location
+	// Maximum number of exchange symbols whose schedules are combined,
+	// 20 when zero
+	Sample int
+}
+
+const defaultCalendarSample = 20
+
+// ExchangeCalendar builds the calendar of an exchange for the days between
+// from and to inclusive, using the schedules of a sample of its symbols.
+func (c *Client) ExchangeCalendar(exchangeID string, from, to time.Time, opts CalendarOptions) (*ExchangeCalendar, error) {
+	symbols, err := c.ExchangeSymbols(exchangeID)
+	if err != nil {
+		return nil, err
+	}
+	sample := opts.Sample
+	if sample <= 0 {
+		sample = defaultCalendarSample
+	}
+	if len(symbols) > sample {
+		symbols = symbols[:sample]
+	}
+	schedules := make([]*Schedule, len(symbols))
+	for i, s := range symbols {
+		if schedules[i], err = c.Schedule(s.ID); err != nil {
+			return nil, err
+		}
+	}
+	return NewExchangeCalendar(exchangeID, schedules, from, to, opts.Location), nil
+}
+
+// NewExchangeCalendar combines schedules into a calendar for the days
+// between from and to inclusive. A day is a trading day when at least one
+// schedule has a main session starting on it.
+func NewExchangeCalendar(exchange string, schedules []*Schedule, from, to time.Time, loc *time.Location) *ExchangeCalendar {
+	if loc == nil {
+		loc = time.UTC
+	}
+	cal := &ExchangeCalendar{
+		Exchange: exchange,
+		Location: loc,
+		Created:  time.Now().UTC(),
+	}
+	closes := make(map[string]int)
+	lengths := make(map[time.Duration]int)
+	var dayLengths []time.Duration // of the closing session, by day
+	first := truncateDay(from.In(loc))
+	last := truncateDay(to.In(loc))
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		day := CalendarDay{Date: d.Format("2006-01-02")}
+		next := d.AddDate(0, 0, 1)
+		covered := false
+		var length time.Duration
+		for _, s := range schedules {
+			if s.Covers(d) || s.Covers(next.Add(-time.Nanosecond)) {
+				covered = true
+			}
+			for _, in := range mainSessions(s) {
+				start := in.Period.Start.In(loc)
+				if start.Before(d) || !start.Before(next) {
+					continue
+				}
+				if day.Open.IsZero() || start.Before(day.Open) {
+					day.Open = start
+				}
+				end := in.Period.End.In(loc)
+				if end.After(day.Close) || (end.Equal(day.Close) && end.Sub(start) > length) {
+					day.Close, length = end, end.Sub(start)
+				}
+			}
+		}
+		switch {
+		case !day.Open.IsZero():
+			day.Status = DayTrading
+			closes[day.Close.Format("15:04")]++
+			lengths[length]++
+		case !covered:
+			day.Status = DayUnknown
+		case d.Weekday() == time.Saturday || d.Weekday() == time.Sunday:
+			day.Status = DayWeekend
+		default:
+			day.Status = DayHoliday
+		}
+		cal.Days = append(cal.Days, day)
+		dayLengths = append(dayLengths, length)
+	}
+
+	best := 0
+	for clock, n := range closes {
+		if n > best || (n == best && clock > cal.RegularClose) {
+			cal.RegularClose, best = clock, n
+		}
+	}
+	best = 0
+	for length, n := range lengths {
+		if n > best || (n == best && length > cal.RegularSession) {
+			cal.RegularSession, best = length, n
+		}
+	}
+	for i := range cal.Days {
+		day := &cal.Days[i]
+		if day.Status == DayTrading && dayLengths[i] < cal.RegularSession {
+			day.Status = DayEarlyClose
+		}
+	}
+	return cal
+}
+
+// mainSessions returns the MainSession intervals of a schedule, or all
+// trading intervals when the schedule does not name a main session.
+func mainSessions(s *Schedule) []SymbolScheduleInterval {
+	var main, open []SymbolScheduleInterval
+	for _, in := range s.Intervals() {
+		if in.Name == SessionMain {
+			main = append(main, in)
+		}
+		if in.Name.IsTrading() {
+			open = append(open, in)
+		}
+	}
+	if len(main) > 0 {
+		return main
+	}
+	return open
+}
+
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// Day returns the calendar entry of the day containing t.
+func (cal *ExchangeCalendar) Day(t time.Time) (CalendarDay, bool) {
+	date := t.In(cal.Location).Format("2006-01-02")
+	i := sort.Search(len(cal.Days), func(i int) bool { return cal.Days[i].Date >= date })
+	if i < len(cal.Days) && cal.Days[i].Date == date {
+		return cal.Days[i], true
+	}
+	return CalendarDay{}, false
+}
+
+// IsTradingDay reports whether the exchange trades, possibly with an early
+// close, on the day containing t.
+func (cal *ExchangeCalendar) IsTradingDay(t time.Time) bool {
+	day, ok := cal.Day(t)
+	return ok && (day.Status == DayTrading || day.Status == DayEarlyClose)
+}
+
+func (cal *ExchangeCalendar) TradingDays() []CalendarDay {
+	return cal.filter(DayTrading, DayEarlyClose)
+}
+
+func (cal *ExchangeCalendar) EarlyCloses() []CalendarDay {
+	return cal.filter(DayEarlyClose)
+}
+
+// Closures returns weekdays without trading.
+func (cal *ExchangeCalendar) Closures() []CalendarDay {
+	return cal.filter(DayHoliday)
+}
+
+func (cal *ExchangeCalendar) filter(statuses ...DayStatus) []CalendarDay {
+	var res []CalendarDay
+	for _, day := range cal.Days {
+		for _, status := range statuses {
+			if day.Status == status {
+				res = append(res, day)
+				break
+			}
+		}
+	}
+	return res
+}
+
+func (cal *ExchangeCalendar) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(cal)
+}
+
+// WriteICS exports closures as all-day events and early closes as events
+// spanning the shortened session.
+func (cal *ExchangeCalendar) WriteICS(w io.Writer) error {
+	const utc = "20060102T150405Z"
+	bw := bufio.NewWriter(w)
+	line := func(format string, args ...interface{}) {
+		bw.WriteString(icsFold(fmt.Sprintf(format, args...)))
+	}
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//exante-api-go//Exchange Calendar//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:%s", icsEscape(cal.Exchange+" trading calendar"))
+	for _, day := range cal.Days {
+		if day.Status != DayHoliday && day.Status != DayEarlyClose {
+			continue
+		}
+		date := strings.Replace(day.Date, "-", "", -1)
+		line("BEGIN:VEVENT")
+		line("UID:%s-%s@exante-api-go", date, icsEscape(strings.Replace(cal.Exchange, " ", "-", -1)))
+		line("DTSTAMP:%s", cal.Created.UTC().Format(utc))
+		if day.Status == DayHoliday {
+			next, _ := time.Parse("2006-01-02", day.Date)
+			line("DTSTART;VALUE=DATE:%s", date)
+			line("DTEND;VALUE=DATE:%s", next.AddDate(0, 0, 1).Format("20060102"))
+			line("SUMMARY:%s", icsEscape(cal.Exchange+" closed"))
+			line("TRANSP:TRANSPARENT")
+		} else {
+			line("DTSTART:%s", day.Open.UTC().Format(utc))
+			line("DTEND:%s", day.Close.UTC().Format(utc))
+			line("SUMMARY:%s", icsEscape(fmt.Sprintf("%s early close at %s",
+				cal.Exchange, day.Close.In(cal.Location).Format("15:04"))))
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// icsFold terminates a content line, folding it into lines of at most 75
+// octets as RFC 5545 requires. Continuation lines start with a space and
+// multi-byte characters are never split.
+func icsFold(s string) string {
+	const limit = 75
+	var b strings.Builder
+	for n := limit; len(s) > n; n = limit - 1 {
+		i := n
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+		b.WriteString(s[:i])
+		b.WriteString("\r\n ")
+		s = s[i:]
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
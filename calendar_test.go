@@ -0,0 +1,187 @@
+package exante
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCalendar(t *testing.T) (*ExchangeCalendar, *time.Location) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	at := func(d, h, m int) time.Time {
+		return time.Date(2017, time.April, d, h, m, 0, 0, ny)
+	}
+	// 10-16 April 2017: Good Friday on the 14th, early close on the 13th
+	var full, partial []SymbolScheduleInterval
+	for d := 10; d <= 16; d++ {
+		closeHour := 16
+		if d == 13 {
+			closeHour = 13
+		}
+		if d >= 14 {
+			full = append(full, interval("Offline", at(d, 0, 0), at(d+1, 0, 0)))
+			continue
+		}
+		full = append(full,
+			interval("Offline", at(d, 0, 0), at(d, 9, 30)),
+			interval("MainSession", at(d, 9, 30), at(d, closeHour, 0)),
+			interval("Offline", at(d, closeHour, 0), at(d+1, 0, 0)))
+		if d <= 11 {
+			partial = append(partial,
+				interval("PreMarket", at(d, 4, 0), at(d, 9, 0)),
+				interval("MainSession", at(d, 9, 0), at(d, 15, 0)))
+		}
+	}
+	schedules := []*Schedule{NewSchedule(full), NewSchedule(partial)}
+	cal := NewExchangeCalendar("NYSE", schedules, at(9, 12, 0), at(17, 12, 0), ny)
+	cal.Created = time.Date(2017, time.April, 1, 0, 0, 0, 0, time.UTC)
+	return cal, ny
+}
+
+func TestExchangeCalendar(t *testing.T) {
+	cal, ny := testCalendar(t)
+
+	statuses := make([]DayStatus, len(cal.Days))
+	for i, day := range cal.Days {
+		statuses[i] = day.Status
+	}
+	assert.Equal(t, []DayStatus{
+		DayUnknown,    // 9, before the schedules
+		DayTrading,    // 10
+		DayTrading,    // 11
+		DayTrading,    // 12
+		DayEarlyClose, // 13
+		DayHoliday,    // 14
+		DayWeekend,    // 15
+		DayWeekend,    // 16
+		DayUnknown,    // 17, after the schedules
+	}, statuses)
+	assert.Equal(t, "16:00", cal.RegularClose)
+
+	day, ok := cal.Day(time.Date(2017, time.April, 10, 20, 0, 0, 0, ny))
+	assert.True(t, ok)
+	assert.Equal(t, "2017-04-10", day.Date)
+	// Combined across symbols
+	assert.True(t, day.Open.Equal(time.Date(2017, time.April, 10, 9, 0, 0, 0, ny)))
+	assert.True(t, day.Close.Equal(time.Date(2017, time.April, 10, 16, 0, 0, 0, ny)))
+
+	assert.True(t, cal.IsTradingDay(time.Date(2017, time.April, 13, 10, 0, 0, 0, ny)))
+	assert.False(t, cal.IsTradingDay(time.Date(2017, time.April, 14, 10, 0, 0, 0, ny)))
+	assert.False(t, cal.IsTradingDay(time.Date(2017, time.May, 1, 10, 0, 0, 0, ny)))
+	assert.Equal(t, 4, len(cal.TradingDays()))
+	assert.Equal(t, "2017-04-13", cal.EarlyCloses()[0].Date)
+	assert.Equal(t, 1, len(cal.Closures()))
+	assert.Equal(t, "2017-04-14", cal.Closures()[0].Date)
+}
+
+func TestExchangeCalendarDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	// 4-15 March 2024, DST starts on the 10th, early close on the 13th.
+	// The calendar is computed in UTC, where the close moves from 21:00
+	// to 20:00.
+	var intervals []SymbolScheduleInterval
+	for d := 4; d <= 15; d++ {
+		date := time.Date(2024, time.March, d, 0, 0, 0, 0, ny)
+		if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+			continue
+		}
+		closeHour := 16
+		if d == 13 {
+			closeHour = 13
+		}
+		intervals = append(intervals, interval(SessionMain,
+			date.Add(9*time.Hour+30*time.Minute), time.Date(2024, time.March, d, closeHour, 0, 0, 0, ny)))
+	}
+	cal := NewExchangeCalendar("NYSE", []*Schedule{NewSchedule(intervals)},
+		time.Date(2024, time.March, 4, 12, 0, 0, 0, ny), time.Date(2024, time.March, 15, 12, 0, 0, 0, ny), nil)
+
+	assert.Equal(t, 6*time.Hour+30*time.Minute, cal.RegularSession)
+	require.Len(t, cal.EarlyCloses(), 1)
+	assert.Equal(t, "2024-03-13", cal.EarlyCloses()[0].Date)
+	assert.Len(t, cal.TradingDays(), 10)
+}
+
+func TestExchangeCalendarOvernight(t *testing.T) {
+	// Sessions from 18:00 to 02:00 the next day, closing at 23:00 on the
+	// 3rd
+	var intervals []SymbolScheduleInterval
+	for d := 1; d <= 4; d++ {
+		open := time.Date(2024, time.January, d, 18, 0, 0, 0, time.UTC)
+		end := open.Add(8 * time.Hour)
+		if d == 3 {
+			end = open.Add(5 * time.Hour)
+		}
+		intervals = append(intervals, interval(SessionMain, open, end))
+	}
+	cal := NewExchangeCalendar("CME", []*Schedule{NewSchedule(intervals)},
+		time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, time.January, 4, 0, 0, 0, 0, time.UTC), nil)
+
+	require.Len(t, cal.EarlyCloses(), 1)
+	assert.Equal(t, "2024-01-03", cal.EarlyCloses()[0].Date)
+	assert.Len(t, cal.TradingDays(), 4)
+}
+
+func TestExchangeCalendarExport(t *testing.T) {
+	cal, _ := testCalendar(t)
+
+	var buf bytes.Buffer
+	if err := cal.WriteICS(&buf); err != nil {
+		t.Fatal(err)
+	}
+	ics := buf.String()
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(ics, "END:VCALENDAR\r\n"))
+	assert.Equal(t, 2, strings.Count(ics, "BEGIN:VEVENT"))
+	assert.Contains(t, ics, "UID:20170414-NYSE@exante-api-go\r\nDTSTAMP:20170401T000000Z\r\n"+
+		"DTSTART;VALUE=DATE:20170414\r\nDTEND;VALUE=DATE:20170415\r\nSUMMARY:NYSE closed\r\n")
+	assert.Contains(t, ics, "DTSTART:20170413T133000Z\r\nDTEND:20170413T170000Z\r\n"+
+		"SUMMARY:NYSE early close at 13:00\r\n")
+
+	buf.Reset()
+	if err := cal.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Exchange     string
+		RegularClose string `json:"regular_close"`
+		Days         []map[string]interface{}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "NYSE", decoded.Exchange)
+	assert.Equal(t, "16:00", decoded.RegularClose)
+	assert.Equal(t, 9, len(decoded.Days))
+	assert.Equal(t, "holiday", decoded.Days[5]["status"])
+	assert.NotContains(t, decoded.Days[5], "open")
+	assert.Equal(t, "2017-04-13T09:30:00-04:00", decoded.Days[4]["open"])
+}
+
+func TestICSFold(t *testing.T) {
+	assert.Equal(t, "SUMMARY:short\r\n", icsFold("SUMMARY:short"))
+
+	long := "SUMMARY:" + strings.Repeat("é", 100)
+	folded := icsFold(long)
+	lines := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+	require.Len(t, lines, 3)
+	for i, line := range lines {
+		assert.LessOrEqual(t, len(line), 75)
+		assert.True(t, utf8.ValidString(line), "characters are not split")
+		if i > 0 {
+			assert.True(t, strings.HasPrefix(line, " "))
+		}
+	}
+	assert.Equal(t, long, strings.Replace(strings.TrimSuffix(folded, "\r\n"), "\r\n ", "", -1))
+}
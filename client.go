@@ -1,11 +1,15 @@
 package exante
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
@@ -29,12 +33,56 @@ const (
 )
 
 type Client struct {
-	conn *http.Client
+	conn    *http.Client
+	baseURL string
 
 	// Auth info
 	clientID      string
 	applicationID string
 	sharedKey     string
+
+	// Clock skew compensation
+	clock         *serverClock
+	issuedAtDelay time.Duration
+
+	middleware []Middleware
+	limiter    *rateLimiter
+
+	ctx context.Context
+}
+
+// Option configures optional Client behaviour.
+type Option func(*Client)
+
+// WithBaseURL points the client to another API root, e.g. the live
+// environment or a test server.
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(url, "/")
+	}
+}
+
+// WithTransport makes the client send requests through rt instead of
+// http.DefaultTransport, e.g. to record or replay API traffic.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.conn.Transport = rt
+	}
+}
+
+// WithIssuedAtBackdate moves the "iat" claim of every token d into the past,
+// so that servers with slightly slower clocks still accept fresh tokens.
+func WithIssuedAtBackdate(d time.Duration) Option {
+	return func(c *Client) {
+		c.issuedAtDelay = d
+	}
+}
+
+// serverClock tracks the estimated difference between the server clock and
+// the local one, learnt from the Date header of API responses.
+type serverClock struct {
+	mu     sync.Mutex
+	offset time.Duration
 }
 
 type Timestamp struct {
@@ -47,7 +95,7 @@ type Symbol struct {
 	Name        string
 	Description string
 	Ticker      string
-	Type        string
+	Type        SymbolType
 	Exchange    string
 	Country     string
 	Currency    string
@@ -55,7 +103,7 @@ type Symbol struct {
 	Group       string
 	Expiration  Timestamp
 	OptionData  struct {
-		Right       string
+		Right       OptionRight
 		StrikePrice float64
 	}
 }
@@ -69,7 +117,7 @@ type SymbolSpecification struct {
 }
 
 type SymbolScheduleInterval struct {
-	Name   string
+	Name   SessionName
 	Period struct {
 		Start Timestamp
 		End   Timestamp
@@ -85,7 +133,7 @@ type Exchange struct {
 type Group struct {
 	Group    string
 	Name     string
-	Types    []string
+	Types    []SymbolType
 	Exchange string
 }
 
@@ -97,20 +145,45 @@ type OHLC struct {
 	Close     float64
 }
 
-func NewClient(clientID, applicationID, sharedKey string) *Client {
-	return &Client{
+func NewClient(clientID, applicationID, sharedKey string, opts ...Option) *Client {
+	c := &Client{
 		conn: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		baseURL:       baseUrl,
 		clientID:      clientID,
 		applicationID: applicationID,
 		sharedKey:     sharedKey,
+		clock:         &serverClock{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithContext returns a shallow copy of the client whose API calls use ctx
+// for cancellation, deadlines and tracing. The copy shares connection and
+// clock state with the original.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	if ctx == nil {
+		panic("nil context")
 	}
+	c2 := *c
+	c2.ctx = ctx
+	return &c2
+}
+
+func (c *Client) context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	return context.Background()
 }
 
 func (c *Client) Symbols() ([]Symbol, error) {
 	var symbols []Symbol
-	if err := c.apiCall("/symbols", "symbols", nil, &symbols); err != nil {
+	if err := c.apiCall(route("/symbols"), "symbols", nil, &symbols); err != nil {
 		return nil, err
 	}
 	return symbols, nil
@@ -118,7 +191,7 @@ func (c *Client) Symbols() ([]Symbol, error) {
 
 func (c *Client) Symbol(id string) (*Symbol, error) {
 	var symbol Symbol
-	if err := c.apiCall("/symbols/"+id, "symbols", nil, &symbol); err != nil {
+	if err := c.apiCall(route("/symbols/{id}", id), "symbols", nil, &symbol); err != nil {
 		return nil, err
 	}
 	return &symbol, nil
@@ -126,7 +199,7 @@ func (c *Client) Symbol(id string) (*Symbol, error) {
 
 func (c *Client) SymbolSpecification(id string) (*SymbolSpecification, error) {
 	var spec SymbolSpecification
-	if err := c.apiCall("/symbols/"+id+"/specification", "symbols", nil, &spec); err != nil {
+	if err := c.apiCall(route("/symbols/{id}/specification", id), "symbols", nil, &spec); err != nil {
 		return nil, err
 	}
 	return &spec, nil
@@ -134,7 +207,7 @@ func (c *Client) SymbolSpecification(id string) (*SymbolSpecification, error) {
 
 func (c *Client) SymbolSchedule(id string) ([]SymbolScheduleInterval, error) {
 	var schedule struct{ Intervals []SymbolScheduleInterval }
-	if err := c.apiCall("/symbols/"+id+"/schedule", "symbols", nil, &schedule); err != nil {
+	if err := c.apiCall(route("/symbols/{id}/schedule", id), "symbols", nil, &schedule); err != nil {
 		return nil, err
 	}
 	return schedule.Intervals, nil
@@ -142,7 +215,7 @@ func (c *Client) SymbolSchedule(id string) ([]SymbolScheduleInterval, error) {
 
 func (c *Client) Exchanges() ([]Exchange, error) {
 	var exchanges []Exchange
-	if err := c.apiCall("/exchanges", "symbols", nil, &exchanges); err != nil {
+	if err := c.apiCall(route("/exchanges"), "symbols", nil, &exchanges); err != nil {
 		return nil, err
 	}
 	return exchanges, nil
@@ -150,27 +223,27 @@ func (c *Client) Exchanges() ([]Exchange, error) {
 
 func (c *Client) ExchangeSymbols(id string) ([]Symbol, error) {
 	var symbols []Symbol
-	if err := c.apiCall("/exchanges/"+id, "symbols", nil, &symbols); err != nil {
+	if err := c.apiCall(route("/exchanges/{id}", id), "symbols", nil, &symbols); err != nil {
 		return nil, err
 	}
 	return symbols, nil
 }
 
-func (c *Client) Types() ([]string, error) {
-	var res []struct{ ID string }
-	if err := c.apiCall("/types", "symbols", nil, &res); err != nil {
+func (c *Client) Types() ([]SymbolType, error) {
+	var res []struct{ ID SymbolType }
+	if err := c.apiCall(route("/types"), "symbols", nil, &res); err != nil {
 		return nil, err
 	}
-	types := make([]string, len(res))
+	types := make([]SymbolType, len(res))
 	for i, v := range res {
 		types[i] = v.ID
 	}
 	return types, nil
 }
 
-func (c *Client) TypeSymbols(id string) ([]Symbol, error) {
+func (c *Client) TypeSymbols(id SymbolType) ([]Symbol, error) {
 	var symbols []Symbol
-	if err := c.apiCall("/types/"+id, "symbols", nil, &symbols); err != nil {
+	if err := c.apiCall(route("/types/{id}", string(id)), "symbols", nil, &symbols); err != nil {
 		return nil, err
 	}
 	return symbols, nil
@@ -178,7 +251,7 @@ func (c *Client) TypeSymbols(id string) ([]Symbol, error) {
 
 func (c *Client) Groups() ([]Group, error) {
 	var groups []Group
-	if err := c.apiCall("/groups", "symbols", nil, &groups); err != nil {
+	if err := c.apiCall(route("/groups"), "symbols", nil, &groups); err != nil {
 		return nil, err
 	}
 	return groups, nil
@@ -186,7 +259,7 @@ func (c *Client) Groups() ([]Group, error) {
 
 func (c *Client) GroupSymbols(id string) ([]Symbol, error) {
 	var symbols []Symbol
-	if err := c.apiCall("/groups/"+id, "symbols", nil, &symbols); err != nil {
+	if err := c.apiCall(route("/groups/{id}", id), "symbols", nil, &symbols); err != nil {
 		return nil, err
 	}
 	return symbols, nil
@@ -194,7 +267,7 @@ func (c *Client) GroupSymbols(id string) ([]Symbol, error) {
 
 func (c *Client) GroupNearestSymbol(id string) (*Symbol, error) {
 	var symbol Symbol
-	if err := c.apiCall("/groups/"+id+"/nearest", "symbols", nil, &symbol); err != nil {
+	if err := c.apiCall(route("/groups/{id}/nearest", id), "symbols", nil, &symbol); err != nil {
 		return nil, err
 	}
 	return &symbol, nil
@@ -203,55 +276,196 @@ func (c *Client) GroupNearestSymbol(id string) (*Symbol, error) {
 func (c *Client) OHLC(symbolId string, duration Duration, from time.Time, to time.Time, size int) ([]OHLC, error) {
 	var candles []OHLC
 	durationStr := strconv.Itoa(int(duration))
-	params := map[string]string{
+	if err := c.apiCall(route("/ohlc/{id}/{duration}", symbolId, durationStr), "ohlc", ohlcParams(from, to, size), &candles); err != nil {
+		return nil, err
+	}
+	return candles, nil
+}
+
+func ohlcParams(from time.Time, to time.Time, size int) map[string]string {
+	return map[string]string{
 		"from": strconv.FormatInt(from.Unix()*1000, 10),
 		"to":   strconv.FormatInt(to.Unix()*1000, 10),
 		"size": strconv.Itoa(size),
 	}
-	if err := c.apiCall("/ohlc/"+symbolId+"/"+durationStr, "ohlc", params, &candles); err != nil {
-		return nil, err
-	}
-	return candles, nil
 }
 
-func (c *Client) apiCall(endpoint string, scope string, params map[string]string, result interface{}) error {
-	req, err := http.NewRequest("GET", baseUrl+endpoint, nil)
+func (c *Client) apiCall(endpoint endpoint, scope string, params map[string]string, result interface{}) error {
+	res, body, err := c.doRequest(endpoint, scope, params, 0)
 	if err != nil {
 		return err
 	}
+	if res.StatusCode == http.StatusUnauthorized && isTokenTimeError(body) {
+		// The clock offset has just been refreshed from this response,
+		// so a new token has a good chance to be accepted.
+		res, body, err = c.doRequest(endpoint, scope, params, 1)
+		if err != nil {
+			return err
+		}
+	}
+	if res.StatusCode != http.StatusOK {
+		return parseError(res, body)
+	}
+	return json.Unmarshal(body, result)
+}
+
+func (c *Client) doRequest(endpoint endpoint, scope string, params map[string]string, attempt int) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(c.context(), "GET", c.baseURL+endpoint.path(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(params) > 0 {
+		query := url.Values{}
+		for k, v := range params {
+			query.Set(k, v)
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+	if c.limiter != nil {
+		if err := c.limiter.wait(req.Context()); err != nil {
+			return nil, nil, err
+		}
+	}
 	token, err := c.signRequest(scope)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	req.Header.Set("Authorization", "Bearer "+token)
-	res, err := c.conn.Do(req)
+	call := &Call{
+		Endpoint:   endpoint.path(),
+		Template:   endpoint.template,
+		PathParams: endpoint.pathParams(),
+		Scope:      scope,
+		Attempt:    attempt,
+		Request:    req,
+	}
+	sent := time.Now()
+	res, err := c.handler()(call)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	defer res.Body.Close()
+	c.clock.update(res.Header.Get("Date"), sent, time.Now())
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-	if res.StatusCode != http.StatusOK {
-		return parseError(res, body)
+	return res, body, nil
+}
+
+// endpoint is an API path template such as "/symbols/{id}" together with
+// the values of its placeholders.
+type endpoint struct {
+	template string
+	args     []string
+}
+
+func route(template string, args ...string) endpoint {
+	return endpoint{template: template, args: args}
+}
+
+func (e endpoint) path() string {
+	var (
+		b strings.Builder
+		i int
+	)
+	e.walk(func(literal, name string) {
+		b.WriteString(literal)
+		if name != "" && i < len(e.args) {
+			b.WriteString(e.args[i])
+			i++
+		}
+	})
+	return b.String()
+}
+
+func (e endpoint) pathParams() map[string]string {
+	if len(e.args) == 0 {
+		return nil
 	}
-	return json.Unmarshal(body, result)
+	params := make(map[string]string, len(e.args))
+	i := 0
+	e.walk(func(_, name string) {
+		if name != "" && i < len(e.args) {
+			params[name] = e.args[i]
+			i++
+		}
+	})
+	return params
+}
+
+// walk calls fn for each literal part of the template followed by the name
+// of the placeholder after it (empty for the trailing literal).
+func (e endpoint) walk(fn func(literal, name string)) {
+	rest := e.template
+	for {
+		start := strings.IndexByte(rest, '{')
+		end := strings.IndexByte(rest, '}')
+		if start < 0 || end < start {
+			fn(rest, "")
+			return
+		}
+		fn(rest[:start], rest[start+1:end])
+		rest = rest[end+1:]
+	}
+}
+
+func (c *Client) handler() Handler {
+	h := Handler(func(call *Call) (*http.Response, error) {
+		return c.conn.Do(call.Request)
+	})
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		h = c.middleware[i](h)
+	}
+	return h
 }
 
 func (c *Client) signRequest(scope string) (string, error) {
-	now := time.Now()
+	now := c.clock.now()
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"iss": c.clientID,
 		"sub": c.applicationID,
 		"aud": []string{scope},
-		"iat": now.Unix(),
+		"iat": now.Add(-c.issuedAtDelay).Unix(),
 		"exp": now.Add(tokenTTL).Unix(),
 	})
 
 	return token.SignedString([]byte(c.sharedKey))
 }
 
+func (sc *serverClock) now() time.Time {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return time.Now().Add(sc.offset)
+}
+
+func (sc *serverClock) update(date string, sent, received time.Time) {
+	if date == "" {
+		return
+	}
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		return
+	}
+	// Date header has one second resolution, so assume the middle of that
+	// second and compare it against the middle of the round trip.
+	serverTime = serverTime.Add(500 * time.Millisecond)
+	local := sent.Add(received.Sub(sent) / 2)
+	sc.mu.Lock()
+	sc.offset = serverTime.Sub(local)
+	sc.mu.Unlock()
+}
+
+func isTokenTimeError(body []byte) bool {
+	msg := strings.ToLower(string(body))
+	for _, s := range []string{"expired", "not valid yet", "not yet valid", "before issued"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
 func parseError(res *http.Response, body []byte) error {
 	// TODO: construct different errors
 	return errors.New(string(body))
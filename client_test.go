@@ -1,9 +1,12 @@
 package exante
 
 import (
+	"context"
+	"net/http"
 	"testing"
 	"time"
 
+	"github.com/dgrijalva/jwt-go"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/h2non/gock.v1"
 )
@@ -93,7 +96,7 @@ func TestSymbols(t *testing.T) {
 	assert.Equal(t, "AAPL.NASDAQ", symbols[0].ID)
 	assert.Equal(t, "Apple", symbols[0].Name)
 	assert.Equal(t, "AAPL", symbols[0].Ticker)
-	assert.Equal(t, "STOCK", symbols[0].Type)
+	assert.Equal(t, SymbolTypeStock, symbols[0].Type)
 	assert.Equal(t, "Apple", symbols[0].Description)
 	assert.Equal(t, "NASDAQ", symbols[0].Exchange)
 	assert.Equal(t, "US", symbols[0].Country)
@@ -103,7 +106,7 @@ func TestSymbols(t *testing.T) {
 	assert.Equal(t, "GOOG.NASDAQ", symbols[1].ID)
 	assert.Equal(t, "Alphabet Class C", symbols[1].Name)
 	assert.Equal(t, "GOOG", symbols[1].Ticker)
-	assert.Equal(t, "STOCK", symbols[1].Type)
+	assert.Equal(t, SymbolTypeStock, symbols[1].Type)
 	assert.Equal(t, "Alphabet Class C", symbols[1].Description)
 	assert.Equal(t, "NASDAQ", symbols[1].Exchange)
 	assert.Equal(t, "US", symbols[1].Country)
@@ -112,7 +115,7 @@ func TestSymbols(t *testing.T) {
 	// RUB Currency
 	assert.Equal(t, "USD/RUB.EXANTE", symbols[2].ID)
 	assert.Equal(t, "USD/RUB", symbols[2].Ticker)
-	assert.Equal(t, "CURRENCY", symbols[2].Type)
+	assert.Equal(t, SymbolTypeCurrency, symbols[2].Type)
 	assert.Equal(t, "USD/RUB", symbols[2].Description)
 	assert.Equal(t, "RUB", symbols[2].Currency)
 	assert.Equal(t, 0.0001, symbols[2].MPI)
@@ -120,7 +123,7 @@ func TestSymbols(t *testing.T) {
 	assert.Equal(t, "6R.CME.M2018", symbols[3].ID)
 	assert.Equal(t, "RUB/USD", symbols[3].Name)
 	assert.Equal(t, "6R", symbols[3].Ticker)
-	assert.Equal(t, "FUTURE", symbols[3].Type)
+	assert.Equal(t, SymbolTypeFuture, symbols[3].Type)
 	assert.Equal(t, "Futures On RUB/USD Jun 2018", symbols[3].Description)
 	assert.Equal(t, "CME", symbols[3].Exchange)
 	assert.Equal(t, "US", symbols[3].Country)
@@ -132,7 +135,7 @@ func TestSymbols(t *testing.T) {
 	assert.Equal(t, "SPX.CBOE.16M2017.P2250", symbols[4].ID)
 	assert.Equal(t, "S&P 500 Index", symbols[4].Name)
 	assert.Equal(t, "SPX", symbols[4].Ticker)
-	assert.Equal(t, "OPTION", symbols[4].Type)
+	assert.Equal(t, SymbolTypeOption, symbols[4].Type)
 	assert.Equal(t, "Options On S&P 500 Index 16 Jun 2017 PUT 2250", symbols[4].Description)
 	assert.Equal(t, "CBOE", symbols[4].Exchange)
 	assert.Equal(t, "US", symbols[4].Country)
@@ -140,7 +143,7 @@ func TestSymbols(t *testing.T) {
 	assert.Equal(t, 0.01, symbols[4].MPI)
 	assert.Equal(t, "SPX.CBOE", symbols[4].Group)
 	assert.Equal(t, Timestamp{time.Unix(1497626100, 0)}, symbols[4].Expiration)
-	assert.Equal(t, "PUT", symbols[4].OptionData.Right)
+	assert.Equal(t, OptionPut, symbols[4].OptionData.Right)
 	assert.Equal(t, 2250.0, symbols[4].OptionData.StrikePrice)
 }
 
@@ -172,7 +175,7 @@ func TestSymbol(t *testing.T) {
 	assert.Equal(t, "AAPL.NASDAQ", symbol.ID)
 	assert.Equal(t, "Apple", symbol.Name)
 	assert.Equal(t, "AAPL", symbol.Ticker)
-	assert.Equal(t, "STOCK", symbol.Type)
+	assert.Equal(t, SymbolTypeStock, symbol.Type)
 	assert.Equal(t, "Apple", symbol.Description)
 	assert.Equal(t, "NASDAQ", symbol.Exchange)
 	assert.Equal(t, "US", symbol.Country)
@@ -226,13 +229,13 @@ func TestSymbolSchedule(t *testing.T) {
 	}
 
 	assert.Equal(t, 3, len(schedule), "Invalid schedule length")
-	assert.Equal(t, "PreMarket", schedule[0].Name)
+	assert.Equal(t, SessionPreMarket, schedule[0].Name)
 	assert.Equal(t, Timestamp{time.Unix(1493020800, 0)}, schedule[0].Period.Start)
 	assert.Equal(t, Timestamp{time.Unix(1493040600, 0)}, schedule[0].Period.End)
-	assert.Equal(t, "MainSession", schedule[1].Name)
+	assert.Equal(t, SessionMain, schedule[1].Name)
 	assert.Equal(t, Timestamp{time.Unix(1493040600, 0)}, schedule[1].Period.Start)
 	assert.Equal(t, Timestamp{time.Unix(1493064000, 0)}, schedule[1].Period.End)
-	assert.Equal(t, "AfterMarket", schedule[2].Name)
+	assert.Equal(t, SessionAfterMarket, schedule[2].Name)
 	assert.Equal(t, Timestamp{time.Unix(1493064000, 0)}, schedule[2].Period.Start)
 	assert.Equal(t, Timestamp{time.Unix(1493078400, 0)}, schedule[2].Period.End)
 }
@@ -323,7 +326,7 @@ func TestExchangeSymbols(t *testing.T) {
 	assert.Equal(t, "LEN.B.NYSE", symbols[0].ID)
 	assert.Equal(t, "Lennar Corporation", symbols[0].Name)
 	assert.Equal(t, "LEN.B", symbols[0].Ticker)
-	assert.Equal(t, "STOCK", symbols[0].Type)
+	assert.Equal(t, SymbolTypeStock, symbols[0].Type)
 	assert.Equal(t, "Lennar Corporation", symbols[0].Description)
 	assert.Equal(t, "NYSE", symbols[0].Exchange)
 	assert.Equal(t, "US", symbols[0].Country)
@@ -333,7 +336,7 @@ func TestExchangeSymbols(t *testing.T) {
 	assert.Equal(t, "BK.NYSE", symbols[1].ID)
 	assert.Equal(t, "Bank Of New York Mellon Corporation", symbols[1].Name)
 	assert.Equal(t, "BK", symbols[1].Ticker)
-	assert.Equal(t, "STOCK", symbols[1].Type)
+	assert.Equal(t, SymbolTypeStock, symbols[1].Type)
 	assert.Equal(t, "Bank Of New York Mellon Corporation", symbols[1].Description)
 	assert.Equal(t, "NYSE", symbols[1].Exchange)
 	assert.Equal(t, "US", symbols[1].Country)
@@ -343,7 +346,7 @@ func TestExchangeSymbols(t *testing.T) {
 	assert.Equal(t, "GJR.NYSE", symbols[2].ID)
 	assert.Equal(t, "Synthetic Fixed-Income Securities", symbols[2].Name)
 	assert.Equal(t, "GJR", symbols[2].Ticker)
-	assert.Equal(t, "STOCK", symbols[2].Type)
+	assert.Equal(t, SymbolTypeStock, symbols[2].Type)
 	assert.Equal(t, "Synthetic Fixed-Income Securities", symbols[2].Description)
 	assert.Equal(t, "NYSE", symbols[2].Exchange)
 	assert.Equal(t, "US", symbols[2].Country)
@@ -376,8 +379,8 @@ func TestTypes(t *testing.T) {
 
 	assert.Equal(t, 8, len(types), "Invalid types length")
 	assert.Equal(t,
-		[]string{"CALENDAR_SPREAD", "FUND", "FX_SPOT", "CURRENCY",
-			"BOND", "FUTURE", "STOCK", "OPTION"}, types)
+		[]SymbolType{SymbolTypeCalendarSpread, SymbolTypeFund, SymbolTypeFXSpot, SymbolTypeCurrency,
+			SymbolTypeBond, SymbolTypeFuture, SymbolTypeStock, SymbolTypeOption}, types)
 }
 
 func TestTypeSymbols(t *testing.T) {
@@ -436,7 +439,7 @@ func TestTypeSymbols(t *testing.T) {
 	assert.Equal(t, "MAXD.OTCMKTS", symbols[0].ID)
 	assert.Equal(t, "Max Sound", symbols[0].Name)
 	assert.Equal(t, "MAXD", symbols[0].Ticker)
-	assert.Equal(t, "STOCK", symbols[0].Type)
+	assert.Equal(t, SymbolTypeStock, symbols[0].Type)
 	assert.Equal(t, "Max Sound", symbols[0].Description)
 	assert.Equal(t, "OTCMKTS", symbols[0].Exchange)
 	assert.Equal(t, "US", symbols[0].Country)
@@ -446,7 +449,7 @@ func TestTypeSymbols(t *testing.T) {
 	assert.Equal(t, "QINC.NASDAQ", symbols[1].ID)
 	assert.Equal(t, "First Trust RBA Quality Income ETF", symbols[1].Name)
 	assert.Equal(t, "QINC", symbols[1].Ticker)
-	assert.Equal(t, "STOCK", symbols[1].Type)
+	assert.Equal(t, SymbolTypeStock, symbols[1].Type)
 	assert.Equal(t, "First Trust RBA Quality Income ETF", symbols[1].Description)
 	assert.Equal(t, "NASDAQ", symbols[1].Exchange)
 	assert.Equal(t, "US", symbols[1].Country)
@@ -456,7 +459,7 @@ func TestTypeSymbols(t *testing.T) {
 	assert.Equal(t, "DGRE.NASDAQ", symbols[2].ID)
 	assert.Equal(t, "WisdomTree Emerging Markets Quality Dividend Growth Fund", symbols[2].Name)
 	assert.Equal(t, "DGRE", symbols[2].Ticker)
-	assert.Equal(t, "STOCK", symbols[2].Type)
+	assert.Equal(t, SymbolTypeStock, symbols[2].Type)
 	assert.Equal(t, "WisdomTree Emerging Markets Quality Dividend Growth Fund", symbols[2].Description)
 	assert.Equal(t, "NASDAQ", symbols[2].Exchange)
 	assert.Equal(t, "US", symbols[2].Country)
@@ -486,17 +489,17 @@ func TestGroups(t *testing.T) {
 	// ABX
 	assert.Equal(t, "ABX", groups[0].Group)
 	assert.Equal(t, "Barrick Gold", groups[0].Name)
-	assert.Equal(t, []string{"OPTION"}, groups[0].Types)
+	assert.Equal(t, []SymbolType{SymbolTypeOption}, groups[0].Types)
 	assert.Equal(t, "CBOE", groups[0].Exchange)
 	// MA
 	assert.Equal(t, "MA", groups[1].Group)
 	assert.Equal(t, "Mastercard", groups[1].Name)
-	assert.Equal(t, []string{"OPTION"}, groups[1].Types)
+	assert.Equal(t, []SymbolType{SymbolTypeOption}, groups[1].Types)
 	assert.Equal(t, "CBOE", groups[1].Exchange)
 	// ATLN
 	assert.Equal(t, "ATLN", groups[2].Group)
 	assert.Equal(t, "Actelion", groups[2].Name)
-	assert.Equal(t, []string{"OPTION"}, groups[2].Types)
+	assert.Equal(t, []SymbolType{SymbolTypeOption}, groups[2].Types)
 	assert.Equal(t, "EUREX", groups[2].Exchange)
 }
 
@@ -538,7 +541,7 @@ func TestGroupSymbols(t *testing.T) {
 	assert.Equal(t, "MA.CBOE.15U2017.P140", symbols[0].ID)
 	assert.Equal(t, "Mastercard", symbols[0].Name)
 	assert.Equal(t, "MA", symbols[0].Ticker)
-	assert.Equal(t, "OPTION", symbols[0].Type)
+	assert.Equal(t, SymbolTypeOption, symbols[0].Type)
 	assert.Equal(t, "Mastercard 15 Sep 2017 PUT 140", symbols[0].Description)
 	assert.Equal(t, "CBOE", symbols[0].Exchange)
 	assert.Equal(t, "US", symbols[0].Country)
@@ -546,7 +549,7 @@ func TestGroupSymbols(t *testing.T) {
 	assert.Equal(t, 0.01, symbols[0].MPI)
 	assert.Equal(t, "MA", symbols[0].Group)
 	assert.Equal(t, Timestamp{time.Unix(1505487600, 0)}, symbols[0].Expiration)
-	assert.Equal(t, "PUT", symbols[0].OptionData.Right)
+	assert.Equal(t, OptionPut, symbols[0].OptionData.Right)
 	assert.Equal(t, 140.0, symbols[0].OptionData.StrikePrice)
 }
 
@@ -580,7 +583,7 @@ func TestGroupNearestSymbol(t *testing.T) {
 	assert.Equal(t, "6R.CME.K2017", symbol.ID)
 	assert.Equal(t, "RUB/USD", symbol.Name)
 	assert.Equal(t, "6R", symbol.Ticker)
-	assert.Equal(t, "FUTURE", symbol.Type)
+	assert.Equal(t, SymbolTypeFuture, symbol.Type)
 	assert.Equal(t, "Futures On RUB/USD May 2017", symbol.Description)
 	assert.Equal(t, "CME", symbol.Exchange)
 	assert.Equal(t, "US", symbol.Country)
@@ -595,6 +598,11 @@ func TestOHLC(t *testing.T) {
 
 	gock.New(baseUrl).
 		Get("/ohlc/AAPL.NASDAQ/86400").
+		MatchParams(map[string]string{
+			"from": "1492992000000",
+			"to":   "1493251200000",
+			"size": "4",
+		}).
 		Reply(200).
 		BodyString(`[
 			{
@@ -662,3 +670,91 @@ func TestOHLC(t *testing.T) {
 	assert.Equal(t, 143.21, candles[3].Low)
 	assert.Equal(t, 143.75, candles[3].Close)
 }
+
+func TestClockSkew(t *testing.T) {
+	defer gock.Off()
+
+	serverTime := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	gock.New(baseUrl).
+		Get("/types").
+		Reply(200).
+		SetHeader("Date", serverTime.Format(http.TimeFormat)).
+		BodyString(`[]`)
+
+	client := NewClient("client", "app", "secret", WithIssuedAtBackdate(5*time.Second))
+	if _, err := client.Types(); err != nil {
+		t.Error(err)
+	}
+
+	signed, err := client.signRequest("symbols")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser := jwt.Parser{SkipClaimsValidation: true}
+	token, err := parser.Parse(signed, func(*jwt.Token) (interface{}, error) {
+		return []byte("secret"), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := token.Claims.(jwt.MapClaims)
+	iat := time.Unix(int64(claims["iat"].(float64)), 0)
+	exp := time.Unix(int64(claims["exp"].(float64)), 0)
+	assert.WithinDuration(t, serverTime.Add(-5*time.Second), iat, 2*time.Second)
+	assert.WithinDuration(t, serverTime.Add(tokenTTL), exp, 2*time.Second)
+}
+
+func TestExpiredTokenRetry(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/types").
+		Reply(401).
+		SetHeader("Date", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)).
+		BodyString(`{"message":"Token is expired"}`)
+	gock.New(baseUrl).
+		Get("/types").
+		Reply(200).
+		BodyString(`[{"id": "STOCK"}]`)
+
+	types, err := NewClient("", "", "").Types()
+
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Equal(t, []SymbolType{SymbolTypeStock}, types)
+	assert.True(t, gock.IsDone())
+}
+
+func TestUnauthorizedNoRetry(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/types").
+		Reply(401).
+		BodyString(`{"message":"Invalid signature"}`)
+
+	_, err := NewClient("", "", "").Types()
+
+	assert.EqualError(t, err, `{"message":"Invalid signature"}`)
+}
+
+func TestWithContext(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/types").
+		Persist().
+		Reply(200).
+		BodyString(`[]`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := NewClient("", "", "")
+	_, err := client.WithContext(ctx).Types()
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotSame(t, client, client.WithContext(ctx))
+	_, err = client.Types()
+	assert.NoError(t, err)
+}
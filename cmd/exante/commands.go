@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zerodivisi0n/exante-api-go"
+)
+
+func runSymbols(e *env, args []string) error {
+	fs := e.flags("symbols")
+	var exchanges, types, groups, currencies, countries list
+	fs.Var(&exchanges, "exchange", "exchange `IDs`")
+	fs.Var(&types, "type", "symbol `types`, e.g. STOCK,FUTURE")
+	fs.Var(&groups, "group", "group `IDs`")
+	fs.Var(&currencies, "currency", "`currencies`")
+	fs.Var(&countries, "country", "`countries`")
+	prefix := fs.String("prefix", "", "ticker, name or description `prefix`, ignoring case")
+	search := fs.String("search", "", "fuzzy search by ticker, name and description")
+	limit := fs.Int("limit", 0, "maximum number of symbols")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	symbols, err := e.client.Symbols()
+	if err != nil {
+		return err
+	}
+	idx := exante.NewSymbolIndex(symbols)
+	if *search != "" {
+		// Matches are ranked, so filters are applied to them afterwards.
+		symbols = nil
+		for _, m := range idx.Search(*search, 0) {
+			symbols = append(symbols, m.Symbol)
+		}
+		idx = exante.NewSymbolIndex(symbols)
+	}
+	q := idx.Query()
+	if len(exchanges) > 0 {
+		q.Exchange(exchanges...)
+	}
+	if len(types) > 0 {
+		st := make([]exante.SymbolType, len(types))
+		for i, t := range types {
+			st[i] = exante.SymbolType(strings.ToUpper(t))
+		}
+		q.Type(st...)
+	}
+	if len(groups) > 0 {
+		q.Group(groups...)
+	}
+	if len(currencies) > 0 {
+		q.Currency(currencies...)
+	}
+	if len(countries) > 0 {
+		q.Country(countries...)
+	}
+	if *prefix != "" {
+		q.Prefix(*prefix)
+	}
+	if *limit > 0 {
+		q.Limit(*limit)
+	}
+	return writeSymbols(e.out, q.All())
+}
+
+func runSymbol(e *env, args []string) error {
+	id, err := oneArg(e.flags("symbol"), args)
+	if err != nil {
+		return err
+	}
+	symbol, err := e.client.Symbol(id)
+	if err != nil {
+		return err
+	}
+	return writeSymbols(e.out, []exante.Symbol{*symbol})
+}
+
+func runSpec(e *env, args []string) error {
+	id, err := oneArg(e.flags("spec"), args)
+	if err != nil {
+		return err
+	}
+	spec, err := e.client.SymbolSpecification(id)
+	if err != nil {
+		return err
+	}
+	return e.out.write(spec,
+		[]string{"Leverage", "LotSize", "ContractMultiplier", "PriceUnit", "Units"},
+		[][]string{{number(spec.Leverage), number(spec.LotSize), number(spec.ContractMultiplier), number(spec.PriceUnit), spec.Units}})
+}
+
+func runSchedule(e *env, args []string) error {
+	id, err := oneArg(e.flags("schedule"), args)
+	if err != nil {
+		return err
+	}
+	intervals, err := e.client.SymbolSchedule(id)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, len(intervals))
+	for i, in := range intervals {
+		rows[i] = []string{string(in.Name), timestamp(in.Period.Start.Time), timestamp(in.Period.End.Time)}
+	}
+	return e.out.write(intervals, []string{"Name", "Start", "End"}, rows)
+}
+
+func runExchanges(e *env, args []string) error {
+	if err := e.flags("exchanges").Parse(args); err != nil {
+		return err
+	}
+	exchanges, err := e.client.Exchanges()
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, len(exchanges))
+	for i, ex := range exchanges {
+		rows[i] = []string{ex.ID, ex.Name, ex.Country}
+	}
+	return e.out.write(exchanges, []string{"ID", "Name", "Country"}, rows)
+}
+
+func runGroups(e *env, args []string) error {
+	fs := e.flags("groups")
+	var exchanges, types list
+	fs.Var(&exchanges, "exchange", "exchange `IDs`")
+	fs.Var(&types, "type", "symbol `types`")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	groups, err := e.client.Groups()
+	if err != nil {
+		return err
+	}
+	filtered := []exante.Group{}
+	var rows [][]string
+	for _, g := range groups {
+		if len(exchanges) > 0 && !contains(exchanges, g.Exchange) {
+			continue
+		}
+		groupTypes := make([]string, len(g.Types))
+		matched := len(types) == 0
+		for i, t := range g.Types {
+			groupTypes[i] = string(t)
+			matched = matched || contains(types, string(t))
+		}
+		if !matched {
+			continue
+		}
+		filtered = append(filtered, g)
+		rows = append(rows, []string{g.Group, g.Name, g.Exchange, strings.Join(groupTypes, ",")})
+	}
+	return e.out.write(filtered, []string{"Group", "Name", "Exchange", "Types"}, rows)
+}
+
+func runTypes(e *env, args []string) error {
+	if err := e.flags("types").Parse(args); err != nil {
+		return err
+	}
+	types, err := e.client.Types()
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, len(types))
+	for i, t := range types {
+		rows[i] = []string{string(t)}
+	}
+	return e.out.write(types, []string{"Type"}, rows)
+}
+
+func runNearest(e *env, args []string) error {
+	group, err := oneArg(e.flags("nearest"), args)
+	if err != nil {
+		return err
+	}
+	symbol, err := e.client.GroupNearestSymbol(group)
+	if err != nil {
+		return err
+	}
+	return writeSymbols(e.out, []exante.Symbol{*symbol})
+}
+
+func runOHLC(e *env, args []string) error {
+	fs := e.flags("ohlc")
+	durationFlag := fs.String("duration", "1d", "candle duration: 1m, 5m, 10m, 15m, 1h, 6h, 1d or seconds")
+	fromFlag := fs.String("from", "", "start `time`, RFC 3339 or YYYY-MM-DD (default: size candles before -to)")
+	toFlag := fs.String("to", "", "end `time`, RFC 3339 or YYYY-MM-DD (default: now)")
+	size := fs.Int("size", 100, "maximum number of candles")
+	id, err := oneArg(fs, args)
+	if err != nil {
+		return err
+	}
+	duration, err := parseDuration(*durationFlag)
+	if err != nil {
+		return err
+	}
+	to := time.Now()
+	if *toFlag != "" {
+		if to, err = parseTime(*toFlag); err != nil {
+			return err
+		}
+	}
+	from := to.Add(-time.Duration(*size) * time.Duration(duration) * time.Second)
+	if *fromFlag != "" {
+		if from, err = parseTime(*fromFlag); err != nil {
+			return err
+		}
+	}
+	candles, err := e.client.OHLC(id, duration, from, to, *size)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, len(candles))
+	for i, c := range candles {
+		rows[i] = []string{timestamp(c.Timestamp.Time), number(c.Open), number(c.High), number(c.Low), number(c.Close)}
+	}
+	return e.out.write(candles, []string{"Timestamp", "Open", "High", "Low", "Close"}, rows)
+}
+
+func writeSymbols(out *output, symbols []exante.Symbol) error {
+	rows := make([][]string, len(symbols))
+	for i, s := range symbols {
+		var expiration, strike string
+		if !s.Expiration.IsZero() {
+			expiration = s.Expiration.UTC().Format("2006-01-02")
+		}
+		if s.OptionData.Right != "" {
+			strike = string(s.OptionData.Right) + " " + number(s.OptionData.StrikePrice)
+		}
+		rows[i] = []string{s.ID, s.Ticker, s.Type.String(), s.Exchange, s.Currency, number(s.MPI), expiration, strike, s.Description}
+	}
+	if symbols == nil {
+		symbols = []exante.Symbol{}
+	}
+	return out.write(symbols, []string{"ID", "Ticker", "Type", "Exchange", "Currency", "MPI", "Expiration", "Option", "Description"}, rows)
+}
+
+var durations = map[string]exante.Duration{
+	"1m":  exante.Duration1Minute,
+	"5m":  exante.Duration5Minutes,
+	"10m": exante.Duration10Minutes,
+	"15m": exante.Duration15Minutes,
+	"1h":  exante.Duration1Hour,
+	"6h":  exante.Duration6Hours,
+	"1d":  exante.Duration1Day,
+}
+
+func parseDuration(s string) (exante.Duration, error) {
+	if d, ok := durations[s]; ok {
+		return d, nil
+	}
+	seconds, err := strconv.Atoi(s)
+	if err != nil || seconds <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return exante.Duration(seconds), nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected RFC 3339 or YYYY-MM-DD", s)
+	}
+	return t, nil
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func number(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zerodivisi0n/exante-api-go"
+)
+
+// runDownload downloads candles of the given symbols, or of the selected
+// ones, into a directory. Running it again resumes an interrupted download,
+// of the symbols in the manifest when none are given.
+func runDownload(e *env, args []string) error {
+	fs := e.flags("download")
+	dir := fs.String("dir", "", "output `directory`")
+	durationFlag := fs.String("duration", "", "candle duration: 1m, 5m, 10m, 15m, 1h, 6h, 1d or seconds (default: from the manifest, or 1d)")
+	fromFlag := fs.String("from", "", "start `time`, RFC 3339 or YYYY-MM-DD (default: from the manifest)")
+	toFlag := fs.String("to", "", "end `time`, exclusive (default: from the manifest, or now)")
+	exchange := fs.String("exchange", "", "download symbols of the `exchange`")
+	symbolType := fs.String("type", "", "download symbols of the `type`")
+	group := fs.String("group", "", "download symbols of the `group`")
+	concurrency := fs.Int("concurrency", exante.DefaultDownloadConcurrency, "symbols downloaded in parallel")
+	pageSize := fs.Int("page-size", exante.DefaultDownloadPageSize, "candles per request")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dir == "" {
+		fs.Usage()
+		return errors.New("download: -dir is required")
+	}
+	if *concurrency < 0 || *pageSize < 0 {
+		return errors.New("download: -concurrency and -page-size must not be negative")
+	}
+
+	// Progress is called from all download workers
+	var progressMu sync.Mutex
+	opts := exante.DownloadOptions{
+		Dir:         *dir,
+		Concurrency: *concurrency,
+		PageSize:    *pageSize,
+		Progress: func(id string, candles int) {
+			progressMu.Lock()
+			defer progressMu.Unlock()
+			fmt.Fprintf(e.stderr, "%s: %d candles\n", id, candles)
+		},
+	}
+	// Resuming needs the exact range of the first run, so unset flags
+	// default to the manifest.
+	manifest, err := exante.LoadDownloadManifest(*dir)
+	if err == nil {
+		opts.Duration, opts.From, opts.To = manifest.Duration, manifest.From, manifest.To
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if *durationFlag != "" {
+		if opts.Duration, err = parseDuration(*durationFlag); err != nil {
+			return err
+		}
+	} else if opts.Duration == 0 {
+		opts.Duration = exante.Duration1Day
+	}
+	if *fromFlag != "" {
+		if opts.From, err = parseTime(*fromFlag); err != nil {
+			return err
+		}
+	}
+	if *toFlag != "" {
+		if opts.To, err = parseTime(*toFlag); err != nil {
+			return err
+		}
+	}
+	if opts.From.IsZero() {
+		return errors.New("download: -from is required")
+	}
+	if opts.To.IsZero() {
+		opts.To = time.Now().UTC().Truncate(time.Duration(opts.Duration) * time.Second)
+	}
+
+	ids := fs.Args()
+	switch {
+	case len(ids) > 0:
+	case *exchange == "" && *symbolType == "" && *group == "":
+		// Selecting nothing would download every symbol of the API
+		if manifest == nil {
+			return errors.New("download: symbol IDs, -exchange, -type or -group are required")
+		}
+		for id := range manifest.Symbols {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		if len(ids) == 0 {
+			return errors.New("download: no symbols in the manifest")
+		}
+	default:
+		symbols, err := exante.SelectSymbols(e.client, exante.SymbolSelection{
+			Exchange: *exchange,
+			Type:     exante.SymbolType(strings.ToUpper(*symbolType)),
+			Group:    *group,
+		})
+		if err != nil {
+			return err
+		}
+		if len(symbols) == 0 {
+			return errors.New("download: no symbols selected")
+		}
+		for _, s := range symbols {
+			ids = append(ids, s.ID)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	m, downloadErr := e.client.Download(ctx, ids, opts)
+	if m == nil {
+		return downloadErr
+	}
+	sort.Strings(ids)
+	rows := make([][]string, len(ids))
+	for i, id := range ids {
+		state := m.Symbols[id]
+		status := "done"
+		switch {
+		case state.Error != "":
+			status = "failed: " + state.Error
+		case !state.Done:
+			status = "incomplete"
+		}
+		rows[i] = []string{id, state.File, strconv.Itoa(state.Candles), status}
+	}
+	if err := e.out.write(m, []string{"ID", "File", "Candles", "Status"}, rows); err != nil {
+		return err
+	}
+	if downloadErr != nil {
+		return fmt.Errorf("download incomplete, run again to resume: %w", downloadErr)
+	}
+	return nil
+}
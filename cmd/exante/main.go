@@ -0,0 +1,201 @@
+// Command exante queries the Exante market data API from the shell.
+//
+// Usage:
+//
+//	exante [-config file] [-format table|json|csv] <command> [flags] [args]
+//
+// Credentials are read from the EXANTE_CLIENT_ID, EXANTE_APPLICATION_ID and
+// EXANTE_SHARED_KEY environment variables, which override the values of the
+// JSON config file (by default exante/config.json in the user config
+// directory):
+//
+//	{"client_id": "...", "application_id": "...", "shared_key": "..."}
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zerodivisi0n/exante-api-go"
+)
+
+type config struct {
+	ClientID      string `json:"client_id"`
+	ApplicationID string `json:"application_id"`
+	SharedKey     string `json:"shared_key"`
+	BaseURL       string `json:"base_url,omitempty"`
+}
+
+// command runs with the arguments following its name.
+type command struct {
+	usage string
+	run   func(env *env, args []string) error
+}
+
+var commands map[string]command
+
+// Assigned in init, as the commands refer to the table for their usage.
+func init() {
+	commands = map[string]command{
+		"symbols":   {"[-exchange X] [-type T] [-group G] [-currency C] [-country C] [-prefix P] [-search Q] [-limit N]", runSymbols},
+		"symbol":    {"ID", runSymbol},
+		"spec":      {"ID", runSpec},
+		"schedule":  {"ID", runSchedule},
+		"exchanges": {"", runExchanges},
+		"groups":    {"[-exchange X] [-type T]", runGroups},
+		"types":     {"", runTypes},
+		"nearest":   {"GROUP", runNearest},
+		"ohlc":      {"[-duration 1d] [-from T] [-to T] [-size N] ID", runOHLC},
+		"download":  {"-dir DIR -from T [-to T] [-duration 1d] [-exchange X] [-type T] [-group G] [-concurrency N] [ID...]", runDownload},
+	}
+}
+
+// env is shared by all commands.
+type env struct {
+	client *exante.Client
+	out    *output
+	stderr io.Writer
+}
+
+func main() {
+	// The usage requested with -h is printed by the flag sets and is not
+	// an error.
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil && !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, "exante:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("exante", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configPath := fs.String("config", os.Getenv("EXANTE_CONFIG"), "config `file`")
+	format := fs.String("format", "table", "output format: table, json or csv")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: exante [flags] <command> [flags] [args]\n\nflags:")
+		fs.PrintDefaults()
+		fmt.Fprintln(stderr, "\ncommands:")
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(stderr, "  %s %s\n", name, commands[name].usage)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("no command")
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fs.Usage()
+		return fmt.Errorf("unknown command %q", fs.Arg(0))
+	}
+	out, err := newOutput(*format, stdout)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	var opts []exante.Option
+	if cfg.BaseURL != "" {
+		opts = append(opts, exante.WithBaseURL(cfg.BaseURL))
+	}
+	e := &env{
+		client: exante.NewClient(cfg.ClientID, cfg.ApplicationID, cfg.SharedKey, opts...),
+		out:    out,
+		stderr: stderr,
+	}
+	// Rows written before a failure are still shown, e.g. the status of
+	// a partly failed download.
+	err = cmd.run(e, fs.Args()[1:])
+	return errors.Join(err, out.flush())
+}
+
+// loadConfig reads the config file, if any, and applies the environment.
+// An explicitly given file must exist.
+func loadConfig(path string) (*config, error) {
+	var cfg config
+	explicit := path != ""
+	if !explicit {
+		if dir, err := os.UserConfigDir(); err == nil {
+			path = filepath.Join(dir, "exante", "config.json")
+		}
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(data, &cfg); err != nil {
+				return nil, fmt.Errorf("config %s: %w", path, err)
+			}
+		case explicit || !os.IsNotExist(err):
+			return nil, err
+		}
+	}
+	for name, field := range map[string]*string{
+		"EXANTE_CLIENT_ID":      &cfg.ClientID,
+		"EXANTE_APPLICATION_ID": &cfg.ApplicationID,
+		"EXANTE_SHARED_KEY":     &cfg.SharedKey,
+		"EXANTE_BASE_URL":       &cfg.BaseURL,
+	} {
+		if v := os.Getenv(name); v != "" {
+			*field = v
+		}
+	}
+	if cfg.ClientID == "" || cfg.ApplicationID == "" || cfg.SharedKey == "" {
+		return nil, errors.New("missing credentials, set EXANTE_CLIENT_ID, EXANTE_APPLICATION_ID and EXANTE_SHARED_KEY or use a config file")
+	}
+	return &cfg, nil
+}
+
+// flags creates the flag set of a command.
+func (e *env) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: exante %s %s\n", name, commands[name].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// oneArg parses the flags of a command expecting a single argument.
+func oneArg(fs *flag.FlagSet, args []string) (string, error) {
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return "", fmt.Errorf("%s: expected 1 argument, got %d", fs.Name(), fs.NArg())
+	}
+	return fs.Arg(0), nil
+}
+
+// list is a repeatable, comma separated flag value.
+type list []string
+
+func (l *list) String() string { return strings.Join(*l, ",") }
+
+func (l *list) Set(v string) error {
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			*l = append(*l, s)
+		}
+	}
+	return nil
+}
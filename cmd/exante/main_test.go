@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zerodivisi0n/exante-api-go"
+	"github.com/zerodivisi0n/exante-api-go/exantetest"
+)
+
+func testServer(t *testing.T) *exantetest.Server {
+	s := exantetest.NewServer()
+	t.Cleanup(s.Close)
+	s.AddSymbols(
+		exante.Symbol{ID: "AAPL.NASDAQ", Ticker: "AAPL", Type: exante.SymbolTypeStock, Exchange: "NASDAQ", Currency: "USD", MPI: 0.01, Description: "Apple Inc."},
+		exante.Symbol{ID: "MSFT.NASDAQ", Ticker: "MSFT", Type: exante.SymbolTypeStock, Exchange: "NASDAQ", Currency: "USD", MPI: 0.01, Description: "Microsoft"},
+		exante.Symbol{ID: "SAP.XETRA", Ticker: "SAP", Type: exante.SymbolTypeStock, Exchange: "XETRA", Currency: "EUR", MPI: 0.01},
+	)
+	s.AddExchanges(exante.Exchange{ID: "NASDAQ", Name: "NASDAQ", Country: "US"})
+	s.AddOHLC("AAPL.NASDAQ", exante.Duration1Day,
+		exante.OHLC{Timestamp: exante.Timestamp{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}, Open: 187.15, High: 188.44, Low: 183.89, Close: 185.64},
+	)
+	t.Setenv("EXANTE_CONFIG", "")
+	t.Setenv("EXANTE_CLIENT_ID", exantetest.ClientID)
+	t.Setenv("EXANTE_APPLICATION_ID", exantetest.ApplicationID)
+	t.Setenv("EXANTE_SHARED_KEY", exantetest.SharedKey)
+	t.Setenv("EXANTE_BASE_URL", s.URL)
+	return s
+}
+
+func execute(t *testing.T, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	err := run(args, &stdout, &stderr)
+	return stdout.String(), err
+}
+
+func TestSymbols(t *testing.T) {
+	testServer(t)
+
+	out, err := execute(t, "symbols", "-exchange", "NASDAQ")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], "ID "))
+	assert.True(t, strings.HasPrefix(lines[1], "AAPL.NASDAQ "))
+
+	out, err = execute(t, "-format", "csv", "symbols", "-currency", "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, "ID,Ticker,Type,Exchange,Currency,MPI,Expiration,Option,Description\nSAP.XETRA,SAP,STOCK,XETRA,EUR,0.01,,,\n", out)
+
+	out, err = execute(t, "-format", "json", "symbols", "-search", "microsoft")
+	require.NoError(t, err)
+	var symbols []exante.Symbol
+	require.NoError(t, json.Unmarshal([]byte(out), &symbols))
+	require.NotEmpty(t, symbols)
+	assert.Equal(t, "MSFT.NASDAQ", symbols[0].ID)
+}
+
+func TestOHLC(t *testing.T) {
+	testServer(t)
+
+	out, err := execute(t, "-format", "csv", "ohlc", "-from", "2024-01-01", "-to", "2024-01-03", "AAPL.NASDAQ")
+	require.NoError(t, err)
+	assert.Equal(t, "Timestamp,Open,High,Low,Close\n2024-01-02T00:00:00Z,187.15,188.44,183.89,185.64\n", out)
+
+	_, err = execute(t, "ohlc", "-duration", "2w", "AAPL.NASDAQ")
+	assert.EqualError(t, err, `invalid duration "2w"`)
+}
+
+func TestConfigFile(t *testing.T) {
+	s := testServer(t)
+	t.Setenv("EXANTE_SHARED_KEY", "")
+	path := filepath.Join(t.TempDir(), "config.json")
+	data, _ := json.Marshal(config{SharedKey: exantetest.SharedKey, ClientID: "overridden by env", BaseURL: "http://unused"})
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	out, err := execute(t, "-config", path, "exchanges")
+	require.NoError(t, err)
+	assert.Contains(t, out, "NASDAQ")
+	assert.Len(t, s.Requests(), 1)
+
+	_, err = execute(t, "-config", filepath.Join(t.TempDir(), "missing.json"), "exchanges")
+	assert.Error(t, err)
+}
+
+func TestUsageErrors(t *testing.T) {
+	testServer(t)
+
+	_, err := execute(t)
+	assert.EqualError(t, err, "no command")
+	_, err = execute(t, "quotes")
+	assert.EqualError(t, err, `unknown command "quotes"`)
+	_, err = execute(t, "symbol")
+	assert.EqualError(t, err, "symbol: expected 1 argument, got 0")
+	_, err = execute(t, "-format", "xml", "types")
+	assert.Error(t, err)
+	_, err = execute(t, "-h")
+	assert.ErrorIs(t, err, flag.ErrHelp, "exits with 0")
+	_, err = execute(t, "download", "-dir", t.TempDir(), "-page-size", "-1", "MSFT.NASDAQ")
+	assert.EqualError(t, err, "download: -concurrency and -page-size must not be negative")
+	_, err = execute(t, "download", "-dir", t.TempDir(), "-from", "2024-01-01")
+	assert.EqualError(t, err, "download: symbol IDs, -exchange, -type or -group are required")
+}
+
+func TestOutputTables(t *testing.T) {
+	var buf bytes.Buffer
+	out, err := newOutput("table", &buf)
+	require.NoError(t, err)
+	require.NoError(t, out.write(nil, []string{"A"}, [][]string{{"1"}}))
+	require.NoError(t, out.write(nil, []string{"B"}, [][]string{{"2"}}))
+	require.NoError(t, out.flush())
+	assert.Equal(t, "A\n1\nB\n2\n", buf.String())
+}
+
+func TestDownload(t *testing.T) {
+	s := testServer(t)
+	for day := 1; day <= 5; day++ {
+		s.AddOHLC("MSFT.NASDAQ", exante.Duration1Day, exante.OHLC{
+			Timestamp: exante.Timestamp{Time: time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)},
+			Close:     float64(day),
+		})
+	}
+	dir := t.TempDir()
+
+	out, err := execute(t, "-format", "csv", "download", "-dir", dir, "-exchange", "NASDAQ", "-from", "2024-01-01", "-to", "2024-01-06", "-page-size", "2")
+	require.NoError(t, err)
+	assert.Equal(t, "ID,File,Candles,Status\nAAPL.NASDAQ,AAPL.NASDAQ.csv,1,done\nMSFT.NASDAQ,MSFT.NASDAQ.csv,5,done\n", out)
+	data, err := os.ReadFile(filepath.Join(dir, "MSFT.NASDAQ.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "2024-01-05T00:00:00Z,0,0,0,5\n")
+
+	// The range is taken from the manifest when resuming.
+	requests := len(s.Requests())
+	_, err = execute(t, "download", "-dir", dir, "MSFT.NASDAQ")
+	require.NoError(t, err)
+	assert.Len(t, s.Requests(), requests)
+
+	// Without IDs or a selection the symbols of the manifest are resumed
+	out, err = execute(t, "-format", "csv", "download", "-dir", dir)
+	require.NoError(t, err)
+	assert.Equal(t, "ID,File,Candles,Status\nAAPL.NASDAQ,AAPL.NASDAQ.csv,1,done\nMSFT.NASDAQ,MSFT.NASDAQ.csv,5,done\n", out)
+	assert.Len(t, s.Requests(), requests)
+
+	_, err = execute(t, "download", "-from", "2024-01-01")
+	assert.EqualError(t, err, "download: -dir is required")
+}
+
+func TestDownloadPartialFailure(t *testing.T) {
+	s := testServer(t)
+	s.AddOHLC("MSFT.NASDAQ", exante.Duration1Day, exante.OHLC{
+		Timestamp: exante.Timestamp{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		Close:     1,
+	})
+	s.InjectFault(exantetest.Fault{Path: "/ohlc/MSFT.NASDAQ/", Status: http.StatusBadGateway})
+
+	out, err := execute(t, "download", "-dir", t.TempDir(), "-from", "2024-01-01", "-to", "2024-01-06", "AAPL.NASDAQ", "MSFT.NASDAQ")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "run again to resume")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 3, "status table printed despite the failure")
+	assert.Regexp(t, `^AAPL\.NASDAQ\s+AAPL\.NASDAQ\.csv\s+1\s+done$`, lines[1])
+	assert.Regexp(t, `^MSFT\.NASDAQ\s+MSFT\.NASDAQ\.csv\s+0\s+failed: `, lines[2])
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// output renders command results. Table and CSV output use the given rows,
+// JSON output encodes the original value.
+type output struct {
+	format string
+	w      io.Writer
+	tw     *tabwriter.Writer
+}
+
+func newOutput(format string, w io.Writer) (*output, error) {
+	switch format {
+	case "table", "json", "csv":
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected table, json or csv", format)
+	}
+	return &output{format: format, w: w}, nil
+}
+
+func (o *output) write(value interface{}, header []string, rows [][]string) error {
+	switch o.format {
+	case "json":
+		enc := json.NewEncoder(o.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(value)
+	case "csv":
+		w := csv.NewWriter(o.w)
+		w.Write(header)
+		w.WriteAll(rows)
+		return w.Error()
+	default:
+		// Every write is a table of its own
+		if err := o.flush(); err != nil {
+			return err
+		}
+		o.tw = tabwriter.NewWriter(o.w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(o.tw, strings.Join(header, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(o.tw, strings.Join(row, "\t"))
+		}
+		return nil
+	}
+}
+
+func (o *output) flush() error {
+	if o.tw != nil {
+		return o.tw.Flush()
+	}
+	return nil
+}
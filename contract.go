@@ -0,0 +1,208 @@
+package exante
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Amount is a money value in a currency.
+type Amount struct {
+	Value    float64
+	Currency string
+}
+
+func (a Amount) String() string {
+	return fmt.Sprintf("%g %s", a.Value, a.Currency)
+}
+
+// CurrencyConverter provides exchange rates: Rate returns how many units
+// of to one unit of from is worth.
+type CurrencyConverter interface {
+	Rate(from, to string) (float64, error)
+}
+
+// Rates is a static CurrencyConverter keyed by "FROM/TO" pairs, e.g.
+// "USD/RUB". Inverse pairs are derived when missing.
+type Rates map[string]float64
+
+func (r Rates) Rate(from, to string) (float64, error) {
+	if strings.EqualFold(from, to) {
+		return 1, nil
+	}
+	if rate, ok := r[from+"/"+to]; ok {
+		return rate, nil
+	}
+	if rate, ok := r[to+"/"+from]; ok && rate != 0 {
+		return 1 / rate, nil
+	}
+	return 0, fmt.Errorf("no rate for %s/%s", from, to)
+}
+
+// Convert returns the amount in another currency.
+func (a Amount) Convert(to string, rates CurrencyConverter) (Amount, error) {
+	rate, err := rates.Rate(a.Currency, to)
+	if err != nil {
+		return Amount{}, err
+	}
+	value, err := finite("amount", a.Value)
+	if err != nil {
+		return Amount{}, err
+	}
+	r, err := finite("rate", rate)
+	if err != nil {
+		return Amount{}, err
+	}
+	return Amount{Value: value.Mul(r).Float64(), Currency: to}, nil
+}
+
+// ContractCalculator computes contract values and position sizes from the
+// specification of a symbol. Quantities are in the units the API uses for
+// orders, i.e. shares or contracts. Results are in the symbol currency.
+type ContractCalculator struct {
+	symbol Symbol
+	spec   SymbolSpecification
+}
+
+func NewContractCalculator(symbol Symbol, spec SymbolSpecification) *ContractCalculator {
+	return &ContractCalculator{symbol: symbol, spec: spec}
+}
+
+// ContractCalculator fetches the specification of a symbol and returns a
+// calculator for it.
+func (c *Client) ContractCalculator(symbol Symbol) (*ContractCalculator, error) {
+	spec, err := c.SymbolSpecification(symbol.ID)
+	if err != nil {
+		return nil, err
+	}
+	return NewContractCalculator(symbol, *spec), nil
+}
+
+// pointValue is the value of one unit of price for one contract.
+func (cc *ContractCalculator) pointValue() (Decimal, error) {
+	multiplier, err := orOne("contract multiplier", cc.spec.ContractMultiplier)
+	if err != nil {
+		return Decimal{}, err
+	}
+	unit, err := orOne("price unit", cc.spec.PriceUnit)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return multiplier.Mul(unit), nil
+}
+
+func (cc *ContractCalculator) amount(d Decimal) Amount {
+	return Amount{Value: d.Float64(), Currency: cc.symbol.Currency}
+}
+
+// NotionalValue returns the value of quantity contracts at price.
+func (cc *ContractCalculator) NotionalValue(price, quantity float64) (Amount, error) {
+	notional, err := cc.notional(price, quantity)
+	if err != nil {
+		return Amount{}, err
+	}
+	return cc.amount(notional), nil
+}
+
+func (cc *ContractCalculator) notional(price, quantity float64) (Decimal, error) {
+	p, err := finite("price", price)
+	if err != nil {
+		return Decimal{}, err
+	}
+	q, err := finite("quantity", quantity)
+	if err != nil {
+		return Decimal{}, err
+	}
+	point, err := cc.pointValue()
+	if err != nil {
+		return Decimal{}, err
+	}
+	return p.Mul(q).Mul(point), nil
+}
+
+// TickValue returns the value of a price move of one MPI for one contract.
+func (cc *ContractCalculator) TickValue() (Amount, error) {
+	mpi, err := finite("MPI", cc.symbol.MPI)
+	if err != nil {
+		return Amount{}, err
+	}
+	point, err := cc.pointValue()
+	if err != nil {
+		return Amount{}, err
+	}
+	return cc.amount(mpi.Mul(point)), nil
+}
+
+// Margin returns the margin required to hold quantity contracts at price.
+// Leverage is the margin ratio of the specification, e.g. 0.2 for 20%; a
+// zero leverage means the position is fully funded.
+func (cc *ContractCalculator) Margin(price, quantity float64) (Amount, error) {
+	margin, err := cc.margin(price, quantity)
+	if err != nil {
+		return Amount{}, err
+	}
+	return cc.amount(margin), nil
+}
+
+func (cc *ContractCalculator) margin(price, quantity float64) (Decimal, error) {
+	notional, err := cc.notional(price, quantity)
+	if err != nil {
+		return Decimal{}, err
+	}
+	leverage, err := orOne("leverage", cc.spec.Leverage)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return notional.Mul(leverage), nil
+}
+
+// MaxQuantity returns the largest multiple of the lot size whose margin at
+// price does not exceed capital, given in the symbol currency.
+func (cc *ContractCalculator) MaxQuantity(capital, price float64) (float64, error) {
+	c, err := finite("capital", capital)
+	if err != nil {
+		return 0, err
+	}
+	lot, err := orOne("lot size", cc.spec.LotSize)
+	if err != nil {
+		return 0, err
+	}
+	unitMargin, err := cc.margin(price, 1)
+	if err != nil {
+		return 0, err
+	}
+	lotMargin := unitMargin.Mul(lot)
+	if lotMargin.Sign() <= 0 || c.Sign() <= 0 {
+		return 0, nil
+	}
+	lots, err := c.QuoInt(lotMargin, RoundDown)
+	if err != nil {
+		return 0, err
+	}
+	return Decimal{coef: lots}.Mul(lot).Float64(), nil
+}
+
+// MaxQuantityIn is MaxQuantity for capital held in another currency.
+func (cc *ContractCalculator) MaxQuantityIn(capital Amount, price float64, rates CurrencyConverter) (float64, error) {
+	converted, err := capital.Convert(cc.symbol.Currency, rates)
+	if err != nil {
+		return 0, err
+	}
+	return cc.MaxQuantity(converted.Value, price)
+}
+
+// finite converts a float from the caller, e.g. the quote of a failed
+// request, which may be NaN or infinite.
+func finite(name string, f float64) (Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}, fmt.Errorf("%s must be finite, got %g", name, f)
+	}
+	return DecimalFromFloat(f), nil
+}
+
+func orOne(name string, f float64) (Decimal, error) {
+	if f == 0 {
+		return NewDecimal(1, 0), nil
+	}
+	return finite(name, f)
+}
@@ -0,0 +1,102 @@
+package exante
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestContractCalculatorStock(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/symbols/AAPL.NASDAQ/specification").
+		Reply(200).
+		BodyString(`{"leverage":0.2,"lotSize":1.0,"contractMultiplier":1.0,"priceUnit":1.0,"units":"Shares"}`)
+
+	symbol := Symbol{ID: "AAPL.NASDAQ", Currency: "USD", MPI: 0.01}
+	calc, err := NewClient("", "", "").ContractCalculator(symbol)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, Amount{14363.5, "USD"}, must(calc.NotionalValue(143.635, 100)))
+	assert.Equal(t, Amount{0.01, "USD"}, must(calc.TickValue()))
+	assert.Equal(t, Amount{2872.7, "USD"}, must(calc.Margin(143.635, 100)))
+	assert.Equal(t, 348.0, must(calc.MaxQuantity(10000, 143.635)))
+	assert.Equal(t, 0.0, must(calc.MaxQuantity(10, 143.635)))
+	assert.Equal(t, "2872.7 USD", must(calc.Margin(143.635, 100)).String())
+}
+
+func TestContractCalculatorFuture(t *testing.T) {
+	symbol := Symbol{ID: "6R.CME.M2018", Currency: "USD", MPI: 5e-06}
+	spec := SymbolSpecification{Leverage: 0.05, LotSize: 1, ContractMultiplier: 2500000, PriceUnit: 1}
+	calc := NewContractCalculator(symbol, spec)
+
+	assert.Equal(t, Amount{12.5, "USD"}, must(calc.TickValue()))
+	assert.Equal(t, Amount{87500, "USD"}, must(calc.NotionalValue(0.0175, 2)))
+	assert.Equal(t, Amount{4375, "USD"}, must(calc.Margin(0.0175, 2)))
+	assert.Equal(t, 4.0, must(calc.MaxQuantity(10000, 0.0175)))
+}
+
+func TestContractCalculatorLots(t *testing.T) {
+	symbol := Symbol{ID: "XYZ.LSE", Currency: "GBP", MPI: 0.5}
+	// Quoted in pence, traded in lots of 10
+	spec := SymbolSpecification{LotSize: 10, PriceUnit: 0.01}
+	calc := NewContractCalculator(symbol, spec)
+
+	assert.Equal(t, Amount{0.005, "GBP"}, must(calc.TickValue()))
+	assert.Equal(t, Amount{250, "GBP"}, must(calc.NotionalValue(2500, 10)))
+	// No leverage means fully funded
+	assert.Equal(t, Amount{250, "GBP"}, must(calc.Margin(2500, 10)))
+	assert.Equal(t, 30.0, must(calc.MaxQuantity(999, 2500)))
+
+	rates := Rates{"GBP/USD": 1.25}
+	qty, err := calc.MaxQuantityIn(Amount{1250, "USD"}, 2500, rates)
+	assert.NoError(t, err)
+	assert.Equal(t, 40.0, qty)
+	_, err = calc.MaxQuantityIn(Amount{1250, "EUR"}, 2500, rates)
+	assert.Error(t, err)
+}
+
+func TestContractCalculatorNonFinite(t *testing.T) {
+	calc := NewContractCalculator(Symbol{ID: "AAPL.NASDAQ", Currency: "USD", MPI: 0.01}, SymbolSpecification{})
+
+	_, err := calc.NotionalValue(math.NaN(), 100)
+	assert.Error(t, err)
+	_, err = calc.Margin(143.635, math.Inf(1))
+	assert.Error(t, err)
+	_, err = calc.MaxQuantity(math.NaN(), 143.635)
+	assert.Error(t, err)
+	_, err = Amount{100, "USD"}.Convert("RUB", Rates{"USD/RUB": math.NaN()})
+	assert.Error(t, err)
+	calc = NewContractCalculator(Symbol{ID: "AAPL.NASDAQ", Currency: "USD", MPI: math.Inf(-1)}, SymbolSpecification{})
+	_, err = calc.TickValue()
+	assert.Error(t, err)
+}
+
+// must returns v and fails on err, for calls known to succeed.
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func TestAmountConvert(t *testing.T) {
+	rates := Rates{"USD/RUB": 57.5}
+
+	rub, err := Amount{100, "USD"}.Convert("RUB", rates)
+	assert.NoError(t, err)
+	assert.Equal(t, Amount{5750, "RUB"}, rub)
+	usd, err := Amount{5750, "RUB"}.Convert("USD", rates)
+	assert.NoError(t, err)
+	assert.InDelta(t, 100, usd.Value, 1e-9)
+	same, err := Amount{1, "USD"}.Convert("USD", rates)
+	assert.NoError(t, err)
+	assert.Equal(t, Amount{1, "USD"}, same)
+	_, err = Amount{1, "EUR"}.Convert("USD", rates)
+	assert.Error(t, err)
+}
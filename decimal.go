@@ -0,0 +1,341 @@
+package exante
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Decimal is an exact decimal number. Parsed values keep their original
+// text if it is a valid JSON number, which String and MarshalJSON then
+// return unchanged.
+type Decimal struct {
+	coef *big.Int // nil means zero
+	exp  int32    // value is coef * 10^exp
+	text string
+}
+
+type RoundingMode int
+
+const (
+	// Round half away from zero
+	RoundNearest RoundingMode = iota
+	// Round towards negative infinity
+	RoundDown
+	// Round towards positive infinity
+	RoundUp
+)
+
+var bigTen = big.NewInt(10)
+
+// maxDecimalExponent bounds the exponent of parsed and constructed values,
+// far beyond any price or quantity, so that aligning them never builds huge
+// powers of ten.
+const maxDecimalExponent = 1000
+
+func ParseDecimal(s string) (Decimal, error) {
+	text := s
+	var exp int64
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(s[i+1:], 10, 64)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", text)
+		}
+		exp, s = e, s[:i]
+	}
+	sign := ""
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		if s[0] == '-' {
+			sign = "-"
+		}
+		s = s[1:]
+	}
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	digits := intPart + fracPart
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", text)
+	}
+	coef, _ := new(big.Int).SetString(sign+digits, 10)
+	exp -= int64(len(fracPart))
+	if exp < -maxDecimalExponent || exp > maxDecimalExponent {
+		return Decimal{}, fmt.Errorf("decimal %q out of range", text)
+	}
+	if !json.Valid([]byte(text)) {
+		// e.g. "+1.5" or ".5", which String renders as 1.5 and 0.5
+		text = ""
+	}
+	return Decimal{coef: coef, exp: int32(exp), text: text}, nil
+}
+
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// NewDecimal returns coef * 10^exp. It panics if exp is beyond ±1000, like
+// ParseDecimal rejects such values.
+func NewDecimal(coef int64, exp int32) Decimal {
+	if exp < -maxDecimalExponent || exp > maxDecimalExponent {
+		panic(fmt.Sprintf("decimal exponent %d out of range", exp))
+	}
+	return Decimal{coef: big.NewInt(coef), exp: exp}
+}
+
+// DecimalFromFloat converts f using the shortest decimal representation
+// that parses back to f, so 0.1 becomes exactly 0.1. It panics if f is NaN
+// or infinite, so callers with untrusted values check them first.
+func DecimalFromFloat(f float64) Decimal {
+	d, err := ParseDecimal(strconv.FormatFloat(f, 'g', -1, 64))
+	if err != nil {
+		// NaN and infinities have no decimal representation
+		panic(err)
+	}
+	d.text = ""
+	return d
+}
+
+func (d Decimal) coefficient() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// align returns the coefficients of a and b scaled to a common exponent.
+func align(a, b Decimal) (*big.Int, *big.Int, int32) {
+	ac, bc := a.coefficient(), b.coefficient()
+	switch {
+	case a.exp > b.exp:
+		return scale(ac, a.exp-b.exp), bc, b.exp
+	case b.exp > a.exp:
+		return ac, scale(bc, b.exp-a.exp), a.exp
+	}
+	return ac, bc, a.exp
+}
+
+func scale(x *big.Int, n int32) *big.Int {
+	m := new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+	return m.Mul(m, x)
+}
+
+func (d Decimal) Add(x Decimal) Decimal {
+	a, b, exp := align(d, x)
+	return Decimal{coef: new(big.Int).Add(a, b), exp: exp}
+}
+
+func (d Decimal) Sub(x Decimal) Decimal {
+	a, b, exp := align(d, x)
+	return Decimal{coef: new(big.Int).Sub(a, b), exp: exp}
+}
+
+// Mul panics if the exponent of the product overflows int32, which takes
+// millions of chained multiplications as exponents of parsed and constructed
+// values are bounded.
+func (d Decimal) Mul(x Decimal) Decimal {
+	exp := int64(d.exp) + int64(x.exp)
+	if exp < math.MinInt32 || exp > math.MaxInt32 {
+		panic(fmt.Sprintf("decimal exponent overflow: 10^%d * 10^%d", d.exp, x.exp))
+	}
+	return Decimal{coef: new(big.Int).Mul(d.coefficient(), x.coefficient()), exp: int32(exp)}
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.coefficient()), exp: d.exp}
+}
+
+// Cmp compares d and x and returns -1, 0 or +1.
+func (d Decimal) Cmp(x Decimal) int {
+	a, b, _ := align(d, x)
+	return a.Cmp(b)
+}
+
+func (d Decimal) Equal(x Decimal) bool {
+	return d.Cmp(x) == 0
+}
+
+func (d Decimal) Sign() int {
+	return d.coefficient().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// QuoInt divides d by x and rounds the quotient to an integer.
+func (d Decimal) QuoInt(x Decimal, mode RoundingMode) (*big.Int, error) {
+	a, b, _ := align(d, x)
+	if b.Sign() == 0 {
+		return nil, fmt.Errorf("division of %s by zero", d)
+	}
+	q, r := new(big.Int).QuoRem(a, b, new(big.Int))
+	if r.Sign() == 0 {
+		return q, nil
+	}
+	negative := a.Sign() != b.Sign()
+	var away bool
+	switch mode {
+	case RoundDown:
+		away = negative
+	case RoundUp:
+		away = !negative
+	default:
+		twice := new(big.Int).Abs(r)
+		away = twice.Lsh(twice, 1).Cmp(new(big.Int).Abs(b)) >= 0
+	}
+	if away {
+		if negative {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q, nil
+}
+
+// RoundToTick rounds d to a multiple of tick.
+func (d Decimal) RoundToTick(tick Decimal, mode RoundingMode) (Decimal, error) {
+	n, err := d.QuoInt(tick, mode)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return Decimal{coef: n.Mul(n, tick.coefficient()), exp: tick.exp}, nil
+}
+
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String returns the original text of parsed values, or a plain decimal
+// representation without exponent otherwise.
+func (d Decimal) String() string {
+	if d.text != "" {
+		return d.text
+	}
+	s := d.coefficient().String()
+	if d.exp >= 0 {
+		if d.Sign() == 0 {
+			return "0"
+		}
+		return s + strings.Repeat("0", int(d.exp))
+	}
+	sign := ""
+	if s[0] == '-' {
+		sign, s = "-", s[1:]
+	}
+	frac := int(-d.exp)
+	if len(s) <= frac {
+		s = strings.Repeat("0", frac-len(s)+1) + s
+	}
+	return sign + s[:len(s)-frac] + "." + s[len(s)-frac:]
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts both JSON numbers and numeric strings.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		*d = Decimal{}
+		return nil
+	}
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		b = []byte(s)
+	}
+	v, err := ParseDecimal(string(b))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// DecimalSymbol is a Symbol with exact MPI and strike price. The float
+// fields of the embedded Symbol are filled as well.
+type DecimalSymbol struct {
+	Symbol
+	MPI         Decimal
+	StrikePrice Decimal
+}
+
+func (s *DecimalSymbol) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &s.Symbol); err != nil {
+		return err
+	}
+	var exact struct {
+		MPI        Decimal
+		OptionData struct {
+			StrikePrice Decimal
+		}
+	}
+	if err := json.Unmarshal(b, &exact); err != nil {
+		return err
+	}
+	s.MPI = exact.MPI
+	s.StrikePrice = exact.OptionData.StrikePrice
+	return nil
+}
+
+type DecimalSymbolSpecification struct {
+	Leverage           Decimal
+	LotSize            Decimal
+	ContractMultiplier Decimal
+	PriceUnit          Decimal
+	Units              string
+}
+
+type DecimalOHLC struct {
+	Timestamp Timestamp
+	Open      Decimal
+	High      Decimal
+	Low       Decimal
+	Close     Decimal
+}
+
+func (c *Client) SymbolsDecimal() ([]DecimalSymbol, error) {
+	var symbols []DecimalSymbol
+	if err := c.apiCall(route("/symbols"), "symbols", nil, &symbols); err != nil {
+		return nil, err
+	}
+	return symbols, nil
+}
+
+func (c *Client) SymbolDecimal(id string) (*DecimalSymbol, error) {
+	var symbol DecimalSymbol
+	if err := c.apiCall(route("/symbols/{id}", id), "symbols", nil, &symbol); err != nil {
+		return nil, err
+	}
+	return &symbol, nil
+}
+
+func (c *Client) SymbolSpecificationDecimal(id string) (*DecimalSymbolSpecification, error) {
+	var spec DecimalSymbolSpecification
+	if err := c.apiCall(route("/symbols/{id}/specification", id), "symbols", nil, &spec); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+func (c *Client) OHLCDecimal(symbolId string, duration Duration, from time.Time, to time.Time, size int) ([]DecimalOHLC, error) {
+	var candles []DecimalOHLC
+	if err := c.apiCall(route("/ohlc/{id}/{duration}", symbolId, strconv.Itoa(int(duration))), "ohlc", ohlcParams(from, to, size), &candles); err != nil {
+		return nil, err
+	}
+	return candles, nil
+}
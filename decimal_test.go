@@ -0,0 +1,207 @@
+package exante
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		text  string
+		plain string
+	}{
+		{"143.635", "143.635"},
+		{"5e-06", "0.000005"},
+		{"1.0E-4", "0.00010"},
+		{"-2", "-2"},
+		{"2250", "2250"},
+		{"1.5e3", "1500"},
+		{"-0.01", "-0.01"},
+	}
+	for _, tt := range tests {
+		d, err := ParseDecimal(tt.text)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		assert.Equal(t, tt.text, d.String())
+		assert.Equal(t, tt.plain, d.Add(Decimal{}).String(), tt.text)
+	}
+	for _, text := range []string{"", ".", "-", "1e", "abc", "1.2.3", "0x10", "1e2000000000", "1e1001", "1e-99999999999"} {
+		_, err := ParseDecimal(text)
+		assert.Error(t, err, text)
+	}
+
+	// Text that is not a valid JSON number is not kept
+	for text, want := range map[string]string{"+1.5": "1.5", ".5": "0.5", "1.": "1", "-.25": "-0.25", "01": "1"} {
+		d, err := ParseDecimal(text)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		assert.Equal(t, want, d.String(), text)
+		b, err := json.Marshal(d)
+		assert.NoError(t, err, text)
+		assert.True(t, json.Valid(b), text)
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	a := MustParseDecimal("0.1")
+	b := MustParseDecimal("0.2")
+
+	assert.Equal(t, "0.3", a.Add(b).String())
+	assert.True(t, a.Add(b).Equal(MustParseDecimal("0.30")))
+	assert.Equal(t, "-0.1", a.Sub(b).String())
+	assert.Equal(t, "0.02", a.Mul(b).String())
+	assert.Panics(t, func() { Decimal{exp: math.MaxInt32}.Mul(NewDecimal(1, 1)) })
+	assert.Panics(t, func() { Decimal{exp: math.MinInt32}.Mul(NewDecimal(1, -1)) })
+	assert.Panics(t, func() { NewDecimal(1, math.MaxInt32) })
+	assert.Panics(t, func() { NewDecimal(1, -1001) })
+	assert.Equal(t, "1000", NewDecimal(1, 3).String())
+	assert.Panics(t, func() { DecimalFromFloat(math.NaN()) })
+	assert.Panics(t, func() { DecimalFromFloat(math.Inf(1)) })
+	assert.Equal(t, "-0.1", a.Neg().String())
+	assert.Equal(t, -1, a.Cmp(b))
+	assert.Equal(t, 1, b.Cmp(a))
+	assert.Equal(t, 0, MustParseDecimal("1e2").Cmp(NewDecimal(100, 0)))
+	assert.True(t, Decimal{}.IsZero())
+	assert.Equal(t, "0", Decimal{}.String())
+	assert.Equal(t, 143.635, MustParseDecimal("143.635").Float64())
+	assert.Equal(t, "0.1", DecimalFromFloat(0.1).String())
+	assert.Equal(t, "0.000005", DecimalFromFloat(5e-06).String())
+
+	// Summing closes stays exact
+	sum := Decimal{}
+	for i := 0; i < 10; i++ {
+		sum = sum.Add(MustParseDecimal("143.635"))
+	}
+	assert.Equal(t, "1436.350", sum.String())
+}
+
+func TestDecimalRoundToTick(t *testing.T) {
+	tick := MustParseDecimal("5e-06")
+	price := MustParseDecimal("0.0151237")
+
+	down, _ := price.RoundToTick(tick, RoundDown)
+	up, _ := price.RoundToTick(tick, RoundUp)
+	nearest, _ := price.RoundToTick(tick, RoundNearest)
+	assert.Equal(t, "0.015120", down.String())
+	assert.Equal(t, "0.015125", up.String())
+	assert.Equal(t, "0.015125", nearest.String())
+
+	neg, _ := MustParseDecimal("-1.005").RoundToTick(MustParseDecimal("0.01"), RoundDown)
+	assert.Equal(t, "-1.01", neg.String())
+	neg, _ = MustParseDecimal("-1.005").RoundToTick(MustParseDecimal("0.01"), RoundUp)
+	assert.Equal(t, "-1.00", neg.String())
+	neg, _ = MustParseDecimal("-1.005").RoundToTick(MustParseDecimal("0.01"), RoundNearest)
+	assert.Equal(t, "-1.01", neg.String())
+
+	n, err := MustParseDecimal("143.635").QuoInt(MustParseDecimal("0.01"), RoundNearest)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(14364), n.Int64())
+	_, err = price.RoundToTick(Decimal{}, RoundDown)
+	assert.Error(t, err)
+}
+
+func TestDecimalJSON(t *testing.T) {
+	var v struct {
+		A, B, C Decimal
+	}
+	err := json.Unmarshal([]byte(`{"a": 1.0E-4, "b": "143.635", "c": null}`), &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "1.0E-4", v.A.String())
+	assert.Equal(t, "143.635", v.B.String())
+	assert.True(t, v.C.IsZero())
+
+	b, err := json.Marshal(v)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"A":1.0E-4,"B":143.635,"C":0}`, string(b))
+	assert.Error(t, json.Unmarshal([]byte(`{"a": "x"}`), &v))
+}
+
+func TestSymbolsDecimal(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/symbols").
+		Reply(200).
+		BodyString(`[
+		{
+			"id": "6R.CME.M2018",
+			"ticker": "6R",
+			"type": "FUTURE",
+			"mpi": 5e-06,
+			"expiration": 1529028000000
+		},
+		{
+			"id": "SPX.CBOE.16M2017.P2250",
+			"type": "OPTION",
+			"mpi": 0.01,
+			"optionData": {"right": "PUT", "strikePrice": 2250.5}
+		}
+	]`)
+
+	symbols, err := NewClient("", "", "").SymbolsDecimal()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(symbols))
+	assert.Equal(t, "6R.CME.M2018", symbols[0].ID)
+	assert.Equal(t, "5e-06", symbols[0].MPI.String())
+	assert.Equal(t, 5e-06, symbols[0].Symbol.MPI)
+	assert.Equal(t, Timestamp{time.Unix(1529028000, 0)}, symbols[0].Expiration)
+	assert.Equal(t, "0.01", symbols[1].MPI.String())
+	assert.Equal(t, "2250.5", symbols[1].StrikePrice.String())
+	assert.Equal(t, OptionPut, symbols[1].OptionData.Right)
+}
+
+func TestSymbolSpecificationDecimal(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/symbols/AAPL.NASDAQ/specification").
+		Reply(200).
+		BodyString(`{"leverage":0.2,"lotSize":1.0,"contractMultiplier":1.0,"priceUnit":1.0,"units":"Shares"}`)
+
+	spec, err := NewClient("", "", "").SymbolSpecificationDecimal("AAPL.NASDAQ")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "0.2", spec.Leverage.String())
+	assert.Equal(t, "1.0", spec.LotSize.String())
+	assert.Equal(t, "1.0", spec.ContractMultiplier.String())
+	assert.Equal(t, "1.0", spec.PriceUnit.String())
+	assert.Equal(t, "Shares", spec.Units)
+}
+
+func TestOHLCDecimal(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/ohlc/AAPL.NASDAQ/86400").
+		Reply(200).
+		BodyString(`[{"timestamp":1493251200000,"open":143.625,"high":144.15,"low":143.315,"close":143.635}]`)
+
+	candles, err := NewClient("", "", "").OHLCDecimal("AAPL.NASDAQ", Duration1Day,
+		time.Unix(1493251200, 0), time.Unix(1493251200, 0), 1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(candles))
+	assert.Equal(t, Timestamp{time.Unix(1493251200, 0)}, candles[0].Timestamp)
+	assert.Equal(t, "143.625", candles[0].Open.String())
+	assert.Equal(t, "144.15", candles[0].High.String())
+	assert.Equal(t, "143.315", candles[0].Low.String())
+	assert.Equal(t, "143.635", candles[0].Close.String())
+}
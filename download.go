@@ -0,0 +1,335 @@
+package exante
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DownloadManifestFile is the name of the checkpoint manifest in the
+	// download directory.
+	DownloadManifestFile = "manifest.json"
+
+	DefaultDownloadPageSize    = 1000
+	DefaultDownloadConcurrency = 4
+
+	// downloadCheckpointInterval bounds how often the manifest is rewritten
+	// while symbols are in progress. A crash loses at most that much
+	// progress.
+	downloadCheckpointInterval = 5 * time.Second
+)
+
+// SymbolSelection selects symbols by exchange, type and group. Empty fields
+// match any symbol.
+type SymbolSelection struct {
+	Exchange string
+	Type     SymbolType
+	Group    string
+}
+
+// SelectSymbols lists the symbols matching sel, using the narrowest API
+// listing available and filtering it by the remaining fields.
+func SelectSymbols(source SymbolSource, sel SymbolSelection) ([]Symbol, error) {
+	var (
+		symbols []Symbol
+		err     error
+	)
+	switch {
+	case sel.Group != "":
+		symbols, err = source.GroupSymbols(sel.Group)
+		sel.Group = ""
+	case sel.Exchange != "":
+		symbols, err = source.ExchangeSymbols(sel.Exchange)
+		sel.Exchange = ""
+	case sel.Type != "":
+		symbols, err = source.TypeSymbols(sel.Type)
+		sel.Type = ""
+	default:
+		symbols, err = source.Symbols()
+	}
+	if err != nil {
+		return nil, err
+	}
+	var res []Symbol
+	for _, s := range symbols {
+		if (sel.Exchange == "" || s.Exchange == sel.Exchange) &&
+			(sel.Type == "" || s.Type == sel.Type) {
+			res = append(res, s)
+		}
+	}
+	return res, nil
+}
+
+type DownloadOptions struct {
+	// Directory of the per-symbol CSV files and the manifest
+	Dir      string
+	Duration Duration
+	// Candles in [From, To) are downloaded
+	From time.Time
+	To   time.Time
+	// Parallel symbols, DefaultDownloadConcurrency when zero
+	Concurrency int
+	// Candles per request, DefaultDownloadPageSize when zero
+	PageSize int
+	// Progress is called after every page written, possibly concurrently.
+	Progress func(id string, candles int)
+}
+
+// DownloadManifest is the checkpoint of a download, saved in the download
+// directory when a symbol completes and periodically while it is in
+// progress.
+type DownloadManifest struct {
+	Duration Duration                  `json:"duration"`
+	From     time.Time                 `json:"from"`
+	To       time.Time                 `json:"to"`
+	Symbols  map[string]*DownloadState `json:"symbols"`
+}
+
+type DownloadState struct {
+	File string `json:"file"`
+	// Start of the first page not written yet
+	Next time.Time `json:"next"`
+	// Size of the file after the last page written. Anything beyond was
+	// written by an interrupted run and is discarded on resume.
+	Offset  int64  `json:"offset"`
+	Candles int    `json:"candles"`
+	Done    bool   `json:"done"`
+	Error   string `json:"error,omitempty"`
+}
+
+// LoadDownloadManifest reads the manifest of a download directory.
+func LoadDownloadManifest(dir string) (*DownloadManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, DownloadManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var m DownloadManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", DownloadManifestFile, err)
+	}
+	return &m, nil
+}
+
+// Download fetches the candles of many symbols into one CSV file per
+// symbol, with at most opts.Concurrency symbols in parallel. Progress is
+// checkpointed to the manifest, so that calling Download again with the
+// same options resumes where a failed or interrupted run stopped. Failed
+// symbols are reported with a BatchError.
+func (c *Client) Download(ctx context.Context, ids []string, opts DownloadOptions) (*DownloadManifest, error) {
+	if opts.Concurrency == 0 {
+		opts.Concurrency = DefaultDownloadConcurrency
+	}
+	if opts.PageSize == 0 {
+		opts.PageSize = DefaultDownloadPageSize
+	}
+	if opts.Concurrency < 0 || opts.PageSize < 0 {
+		return nil, errors.New("download concurrency and page size must not be negative")
+	}
+	if opts.Duration <= 0 || !opts.From.Before(opts.To) {
+		return nil, errors.New("download needs a duration and a non-empty time range")
+	}
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return nil, err
+	}
+	m, err := LoadDownloadManifest(opts.Dir)
+	switch {
+	case os.IsNotExist(err):
+		m = &DownloadManifest{
+			Duration: opts.Duration,
+			From:     opts.From,
+			To:       opts.To,
+			Symbols:  make(map[string]*DownloadState),
+		}
+	case err != nil:
+		return nil, err
+	case m.Duration != opts.Duration || !m.From.Equal(opts.From) || !m.To.Equal(opts.To):
+		return nil, fmt.Errorf("%s was created for %ds candles from %s to %s", DownloadManifestFile,
+			m.Duration, m.From.Format(time.RFC3339), m.To.Format(time.RFC3339))
+	}
+
+	d := &download{opts: opts, manifest: m}
+	var pending []string
+	for _, id := range ids {
+		state, ok := m.Symbols[id]
+		if !ok {
+			state = &DownloadState{File: downloadFile(id)}
+			m.Symbols[id] = state
+		}
+		state.Error = ""
+		if !state.Done {
+			pending = append(pending, id)
+		}
+	}
+	if err := d.save(); err != nil {
+		return nil, err
+	}
+	errs := c.WithContext(ctx).batch(ctx, pending, opts.Concurrency, func(c *Client, i int) error {
+		return d.symbol(c, pending[i])
+	})
+	if errs != nil {
+		d.mu.Lock()
+		for id, err := range errs.(BatchError) {
+			m.Symbols[id].Error = err.Error()
+		}
+		d.mu.Unlock()
+	}
+	if err := d.save(); err != nil {
+		return m, err
+	}
+	return m, errs
+}
+
+type download struct {
+	opts DownloadOptions
+
+	mu       sync.Mutex // guards manifest, its file and saved
+	manifest *DownloadManifest
+	saved    time.Time
+}
+
+func (d *download) symbol(c *Client, id string) error {
+	d.mu.Lock()
+	state := *d.manifest.Symbols[id]
+	d.mu.Unlock()
+
+	f, err := os.OpenFile(filepath.Join(d.opts.Dir, state.File), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := f.Truncate(state.Offset); err != nil {
+		return err
+	}
+	if _, err := f.Seek(state.Offset, io.SeekStart); err != nil {
+		return err
+	}
+	if state.Offset == 0 {
+		if _, err := f.WriteString("timestamp,open,high,low,close\n"); err != nil {
+			return err
+		}
+	}
+
+	next := state.Next
+	if next.IsZero() {
+		next = d.opts.From
+	}
+	page := time.Duration(d.opts.PageSize) * time.Duration(d.opts.Duration) * time.Second
+	for next.Before(d.opts.To) {
+		end := next.Add(page)
+		if end.After(d.opts.To) {
+			end = d.opts.To
+		}
+		// The API range is inclusive, with second precision.
+		candles, err := c.OHLC(id, d.opts.Duration, next, end.Add(-time.Second), d.opts.PageSize)
+		if err != nil {
+			return err
+		}
+		sort.Slice(candles, func(i, j int) bool {
+			return candles[i].Timestamp.Before(candles[j].Timestamp.Time)
+		})
+		var b strings.Builder
+		n := 0
+		for _, candle := range candles {
+			if candle.Timestamp.Before(next) || !candle.Timestamp.Before(end) {
+				continue
+			}
+			b.WriteString(candle.Timestamp.UTC().Format(time.RFC3339))
+			for _, v := range []float64{candle.Open, candle.High, candle.Low, candle.Close} {
+				b.WriteByte(',')
+				b.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+			}
+			b.WriteByte('\n')
+			n++
+		}
+		if _, err := f.WriteString(b.String()); err != nil {
+			return err
+		}
+		// The page must be durable before the manifest points past it.
+		if err := f.Sync(); err != nil {
+			return err
+		}
+		offset, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		next = end
+		state.Next, state.Offset, state.Candles = next, offset, state.Candles+n
+		state.Done = !next.Before(d.opts.To)
+		if err := d.update(id, state); err != nil {
+			return err
+		}
+		if d.opts.Progress != nil {
+			d.opts.Progress(id, state.Candles)
+		}
+	}
+	return nil
+}
+
+// update records the progress of a symbol. The manifest is written when the
+// symbol is done or the last checkpoint is older than
+// downloadCheckpointInterval, so that workers don't serialize on rewriting
+// it after every page.
+func (d *download) update(id string, state DownloadState) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	*d.manifest.Symbols[id] = state
+	if !state.Done && time.Since(d.saved) < downloadCheckpointInterval {
+		return nil
+	}
+	return d.write()
+}
+
+func (d *download) save() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.write()
+}
+
+// write saves the manifest through a synced temporary file, so that a crash
+// never leaves a truncated manifest behind. Callers hold mu, which also keeps
+// concurrent writes in order.
+func (d *download) write() error {
+	data, err := json.MarshalIndent(d.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(d.opts.Dir, DownloadManifestFile+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(d.opts.Dir, DownloadManifestFile)); err != nil {
+		return err
+	}
+	d.saved = time.Now()
+	return nil
+}
+
+// downloadFile returns the file name for a symbol. Symbol IDs may contain
+// slashes, e.g. currency pairs, so they are escaped reversibly to keep
+// distinct IDs in distinct files.
+func downloadFile(id string) string {
+	return url.PathEscape(id) + ".csv"
+}
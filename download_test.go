@@ -0,0 +1,167 @@
+package exante
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var downloadStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// candleServer answers OHLC calls with a daily candle for every day since
+// downloadStart, closing at the day number. Calls after the failAfter-th
+// one fail when failAfter is positive.
+func candleServer(calls *int32, failAfter int32) Middleware {
+	return func(next Handler) Handler {
+		return func(call *Call) (*http.Response, error) {
+			if n := atomic.AddInt32(calls, 1); failAfter > 0 && n > failAfter {
+				return nil, errors.New("connection reset")
+			}
+			query := call.Request.URL.Query()
+			from, _ := strconv.ParseInt(query.Get("from"), 10, 64)
+			to, _ := strconv.ParseInt(query.Get("to"), 10, 64)
+			size, _ := strconv.Atoi(query.Get("size"))
+			var candles []OHLC
+			for day := time.UnixMilli(to).UTC().Truncate(24 * time.Hour); !day.Before(time.UnixMilli(from)) && len(candles) < size; day = day.AddDate(0, 0, -1) {
+				if day.Before(downloadStart) {
+					break
+				}
+				n := float64(day.Sub(downloadStart) / (24 * time.Hour))
+				candles = append(candles, OHLC{Timestamp: Timestamp{day}, Open: n, High: n, Low: n, Close: n})
+			}
+			body, _ := json.Marshal(candles)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     make(http.Header),
+				Body:       io.NopCloser(bytes.NewReader(body)),
+				Request:    call.Request,
+			}, nil
+		}
+	}
+}
+
+func TestDownload(t *testing.T) {
+	dir := t.TempDir()
+	opts := DownloadOptions{
+		Dir:      dir,
+		Duration: Duration1Day,
+		From:     downloadStart,
+		To:       downloadStart.AddDate(0, 0, 10),
+		PageSize: 3,
+	}
+	var calls int32
+	client := NewClient("", "", "", WithMiddleware(candleServer(&calls, 0)))
+	m, err := client.Download(context.Background(), []string{"AAPL.NASDAQ", "EUR/USD.E.FX"}, opts)
+	require.NoError(t, err)
+	assert.Equal(t, int32(8), calls, "4 pages per symbol")
+
+	data, err := os.ReadFile(filepath.Join(dir, "EUR%2FUSD.E.FX.csv"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 11)
+	assert.Equal(t, "timestamp,open,high,low,close", lines[0])
+	assert.Equal(t, "2024-01-01T00:00:00Z,0,0,0,0", lines[1])
+	assert.Equal(t, "2024-01-10T00:00:00Z,9,9,9,9", lines[10])
+
+	_, err = os.Stat(filepath.Join(dir, "EUR_USD.E.FX.csv"))
+	assert.True(t, os.IsNotExist(err), "distinct from a symbol with an underscore")
+
+	state := m.Symbols["EUR/USD.E.FX"]
+	assert.True(t, state.Done)
+	assert.Equal(t, 10, state.Candles)
+	assert.True(t, state.Next.Equal(opts.To))
+
+	calls = 0
+	_, err = client.Download(context.Background(), []string{"AAPL.NASDAQ", "EUR/USD.E.FX"}, opts)
+	require.NoError(t, err)
+	assert.Zero(t, calls, "completed symbols are skipped")
+
+	opts.To = opts.To.AddDate(0, 0, 1)
+	_, err = client.Download(context.Background(), []string{"AAPL.NASDAQ"}, opts)
+	assert.Error(t, err, "options differ from the manifest")
+
+	opts.Dir, opts.PageSize = t.TempDir(), -1
+	_, err = client.Download(context.Background(), []string{"AAPL.NASDAQ"}, opts)
+	assert.Error(t, err, "negative page size")
+	opts.PageSize, opts.Concurrency = 0, -1
+	_, err = client.Download(context.Background(), []string{"AAPL.NASDAQ"}, opts)
+	assert.Error(t, err, "negative concurrency")
+}
+
+func TestDownloadResume(t *testing.T) {
+	opts := DownloadOptions{
+		Duration:    Duration1Day,
+		From:        downloadStart,
+		To:          downloadStart.AddDate(0, 0, 20),
+		PageSize:    4,
+		Concurrency: 1,
+	}
+	ids := []string{"AAPL.NASDAQ", "MSFT.NASDAQ"}
+
+	var calls int32
+	opts.Dir = t.TempDir()
+	_, err := NewClient("", "", "", WithMiddleware(candleServer(&calls, 0))).Download(context.Background(), ids, opts)
+	require.NoError(t, err)
+	want, err := os.ReadFile(filepath.Join(opts.Dir, "MSFT.NASDAQ.csv"))
+	require.NoError(t, err)
+
+	opts.Dir = t.TempDir()
+	calls = 0
+	m, err := NewClient("", "", "", WithMiddleware(candleServer(&calls, 7))).Download(context.Background(), ids, opts)
+	var batchErr BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Contains(t, batchErr, "MSFT.NASDAQ")
+	assert.True(t, m.Symbols["AAPL.NASDAQ"].Done)
+	assert.False(t, m.Symbols["MSFT.NASDAQ"].Done)
+	assert.Equal(t, 8, m.Symbols["MSFT.NASDAQ"].Candles)
+	assert.Contains(t, m.Symbols["MSFT.NASDAQ"].Error, "connection reset")
+
+	// Simulate a crash after a page was written but before it was
+	// checkpointed.
+	f, err := os.OpenFile(filepath.Join(opts.Dir, "MSFT.NASDAQ.csv"), os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	f.WriteString("2024-01-09T00:00:00Z,8,8,8,8\n2024-01-10T00:")
+	f.Close()
+
+	calls = 0
+	var progress []int
+	opts.Progress = func(id string, candles int) { progress = append(progress, candles) }
+	m, err = NewClient("", "", "", WithMiddleware(candleServer(&calls, 0))).Download(context.Background(), ids, opts)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), calls, "only the missing pages")
+	assert.Equal(t, []int{12, 16, 20}, progress)
+	assert.Empty(t, m.Symbols["MSFT.NASDAQ"].Error)
+
+	got, err := os.ReadFile(filepath.Join(opts.Dir, "MSFT.NASDAQ.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestSelectSymbols(t *testing.T) {
+	source := &stubMarketData{}
+	symbols, err := SelectSymbols(source, SymbolSelection{Exchange: "NASDAQ"})
+	require.NoError(t, err)
+	assert.Equal(t, []Symbol{{ID: "AAPL.NASDAQ"}}, symbols)
+	assert.Equal(t, 1, source.calls["ExchangeSymbols"])
+
+	symbols, err = SelectSymbols(source, SymbolSelection{Exchange: "NASDAQ", Type: SymbolTypeStock})
+	require.NoError(t, err)
+	assert.Empty(t, symbols, "filtered by type")
+
+	symbols, err = SelectSymbols(source, SymbolSelection{})
+	require.NoError(t, err)
+	assert.Len(t, symbols, 2)
+}
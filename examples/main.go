@@ -1,12 +1,11 @@
 package main
 
 import (
-	"encoding/csv"
 	"fmt"
 	"os"
-	"strconv"
 
 	"github.com/zerodivisi0n/exante-api-go"
+	"github.com/zerodivisi0n/exante-api-go/exanteexport"
 )
 
 func main() {
@@ -26,24 +25,7 @@ func writeExchanges(client *exante.Client) {
 		fmt.Printf("Failed to get exchanges: %v\n", err)
 		return
 	}
-	f, err := os.Create("exante-exchanges.csv")
-	if err != nil {
-		fmt.Printf("Failed to open file: %v\n", err)
-		return
-	}
-	defer f.Close()
-	w := csv.NewWriter(f)
-	w.Write([]string{"ID", "Name", "Country"})
-	for _, ex := range exchanges {
-		if err := w.Write([]string{ex.ID, ex.Name, ex.Country}); err != nil {
-			fmt.Printf("Write failed: %v\n", err)
-			break
-		}
-	}
-	w.Flush()
-	if err := w.Error(); err != nil {
-		fmt.Printf("Error: %v\n", err)
-	}
+	writeCSV("exante-exchanges.csv", exchanges)
 }
 
 func writeSymbols(client *exante.Client) {
@@ -52,31 +34,17 @@ func writeSymbols(client *exante.Client) {
 		fmt.Printf("Failed to get symbols: %v\n", err)
 		return
 	}
-	f, err := os.Create("exante-symbols.csv")
+	writeCSV("exante-symbols.csv", symbols)
+}
+
+func writeCSV[T any](path string, records []T) {
+	f, err := os.Create(path)
 	if err != nil {
 		fmt.Printf("Failed to open file: %v\n", err)
 		return
 	}
 	defer f.Close()
-	w := csv.NewWriter(f)
-	w.Write([]string{"ID", "Name", "Description", "Ticker", "Type", "Exchange", "Country", "Currency", "MPI", "Group"})
-	for _, s := range symbols {
-		if err := w.Write([]string{
-			s.ID, s.Name,
-			s.Description,
-			s.Ticker,
-			s.Type,
-			s.Exchange,
-			s.Country,
-			s.Currency,
-			strconv.FormatFloat(s.MPI, 'g', -1, 64),
-			s.Group}); err != nil {
-			fmt.Printf("Write failed: %v\n", err)
-			break
-		}
-	}
-	w.Flush()
-	if err := w.Error(); err != nil {
-		fmt.Printf("Error: %v\n", err)
+	if err := exanteexport.Write(f, exanteexport.CSV, records, exanteexport.Options{}); err != nil {
+		fmt.Printf("Write failed: %v\n", err)
 	}
 }
@@ -0,0 +1,530 @@
+// Package exanteexport writes API types such as exante.Symbol, Exchange,
+// Group, OHLC, SymbolSpecification and SymbolScheduleInterval as CSV or
+// JSON Lines and reads them back.
+//
+// Nested structs are flattened into dotted columns named after the Go
+// fields, e.g. "OptionData.Right" or "Period.Start". Lists are written to
+// CSV cells as JSON arrays. JSON Lines records are flat objects with the
+// same keys.
+package exanteexport
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zerodivisi0n/exante-api-go"
+)
+
+// Format is the file format of written and read records.
+type Format int
+
+const (
+	CSV Format = iota
+	JSONL
+)
+
+// Special values of Options.TimeFormat. Any other value is a time layout.
+const (
+	TimeUnix      = "unix"   // seconds since the epoch
+	TimeUnixMilli = "unixms" // milliseconds since the epoch, as in the API
+)
+
+type Options struct {
+	// Columns to write, in order. All columns when empty. Ignored when
+	// reading, which uses the CSV header or the JSON keys.
+	Columns []string
+	// Format of timestamps, time.RFC3339 when empty. Zero timestamps are
+	// written as empty values.
+	TimeFormat string
+}
+
+// Columns returns all column names of T.
+func Columns[T any]() ([]string, error) {
+	fields, err := fieldsOf(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.name
+	}
+	return names, nil
+}
+
+// Writer writes records of type T.
+type Writer[T any] struct {
+	format  Format
+	opts    Options
+	fields  []field
+	csv     *csv.Writer
+	jsonl   io.Writer
+	started bool
+}
+
+// NewWriter creates a Writer of the columns selected by opts. Callers must
+// Flush it after the last Write.
+func NewWriter[T any](w io.Writer, format Format, opts Options) (*Writer[T], error) {
+	all, err := fieldsOf(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	fields, err := selectFields(all, opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+	ew := &Writer[T]{format: format, opts: opts, fields: fields}
+	switch format {
+	case CSV:
+		ew.csv = csv.NewWriter(w)
+	case JSONL:
+		ew.jsonl = w
+	default:
+		return nil, fmt.Errorf("unknown format %d", format)
+	}
+	return ew, nil
+}
+
+// Write writes records, preceded by the CSV header on the first call.
+func (w *Writer[T]) Write(records ...T) error {
+	if w.csv != nil && !w.started {
+		header := make([]string, len(w.fields))
+		for i, f := range w.fields {
+			header[i] = f.name
+		}
+		if err := w.csv.Write(header); err != nil {
+			return err
+		}
+	}
+	w.started = true
+	for i := range records {
+		v := reflect.ValueOf(&records[i]).Elem()
+		if w.csv != nil {
+			row := make([]string, len(w.fields))
+			for j, f := range w.fields {
+				row[j] = formatText(v.FieldByIndex(f.index), w.opts.TimeFormat)
+			}
+			if err := w.csv.Write(row); err != nil {
+				return err
+			}
+			continue
+		}
+		obj := make(orderedObject, len(w.fields))
+		for j, f := range w.fields {
+			obj[j] = keyValue{f.name, formatJSON(v.FieldByIndex(f.index), w.opts.TimeFormat)}
+		}
+		if err := obj.writeLine(w.jsonl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes buffered data. The CSV header is written even when there
+// were no records.
+func (w *Writer[T]) Flush() error {
+	if w.csv == nil {
+		return nil
+	}
+	if !w.started {
+		if err := w.Write(); err != nil {
+			return err
+		}
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// Write writes records to w.
+func Write[T any](w io.Writer, format Format, records []T, opts Options) error {
+	ew, err := NewWriter[T](w, format, opts)
+	if err != nil {
+		return err
+	}
+	if err := ew.Write(records...); err != nil {
+		return err
+	}
+	return ew.Flush()
+}
+
+// Read parses records written by Write with the same format and time
+// format.
+func Read[T any](r io.Reader, format Format, opts Options) ([]T, error) {
+	fields, err := fieldsOf(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]field, len(fields))
+	for _, f := range fields {
+		byName[f.name] = f
+	}
+	switch format {
+	case CSV:
+		return readCSV[T](r, byName, opts)
+	case JSONL:
+		return readJSONL[T](r, byName, opts)
+	}
+	return nil, fmt.Errorf("unknown format %d", format)
+}
+
+func readCSV[T any](r io.Reader, byName map[string]field, opts Options) ([]T, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]field, len(header))
+	for i, name := range header {
+		f, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		fields[i] = f
+	}
+	var records []T
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var rec T
+		v := reflect.ValueOf(&rec).Elem()
+		for i, f := range fields {
+			if err := parseText(v.FieldByIndex(f.index), row[i], opts.TimeFormat); err != nil {
+				return nil, fmt.Errorf("line %d, column %s: %w", line, f.name, err)
+			}
+		}
+		records = append(records, rec)
+	}
+}
+
+func readJSONL[T any](r io.Reader, byName map[string]field, opts Options) ([]T, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var records []T
+	for line := 1; ; line++ {
+		var obj map[string]interface{}
+		if err := dec.Decode(&obj); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("record %d: %w", line, err)
+		}
+		var rec T
+		v := reflect.ValueOf(&rec).Elem()
+		for name, value := range obj {
+			f, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("record %d: unknown key %q", line, name)
+			}
+			if err := parseJSON(v.FieldByIndex(f.index), value, opts.TimeFormat); err != nil {
+				return nil, fmt.Errorf("record %d, key %s: %w", line, name, err)
+			}
+		}
+		records = append(records, rec)
+	}
+}
+
+// field is a leaf column of a flattened struct.
+type field struct {
+	name  string
+	index []int
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	timestampType = reflect.TypeOf(exante.Timestamp{})
+	textType      = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func fieldsOf(t reflect.Type) ([]field, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct", t)
+	}
+	var fields []field
+	var walk func(t reflect.Type, prefix string, index []int)
+	walk = func(t reflect.Type, prefix string, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			idx := append(index[:len(index):len(index)], i)
+			name := prefix + sf.Name
+			if sf.Anonymous {
+				name = strings.TrimSuffix(prefix, ".")
+			}
+			if sf.Type.Kind() == reflect.Struct && !isLeaf(sf.Type) {
+				if sf.Anonymous {
+					walk(sf.Type, prefix, idx)
+				} else {
+					walk(sf.Type, name+".", idx)
+				}
+				continue
+			}
+			fields = append(fields, field{name: name, index: idx})
+		}
+	}
+	walk(t, "", nil)
+	return fields, nil
+}
+
+func isLeaf(t reflect.Type) bool {
+	return t == timeType || t == timestampType || t.Implements(textType) || reflect.PointerTo(t).Implements(textType)
+}
+
+func selectFields(all []field, columns []string) ([]field, error) {
+	if len(columns) == 0 {
+		return all, nil
+	}
+	fields := make([]field, len(columns))
+	for i, name := range columns {
+		found := false
+		for _, f := range all {
+			if f.name == name {
+				fields[i], found = f, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+	}
+	return fields, nil
+}
+
+func timeOf(v reflect.Value) (time.Time, bool) {
+	switch v.Type() {
+	case timeType:
+		return v.Interface().(time.Time), true
+	case timestampType:
+		return v.Interface().(exante.Timestamp).Time, true
+	}
+	return time.Time{}, false
+}
+
+func setTime(v reflect.Value, t time.Time) {
+	if v.Type() == timestampType {
+		v.Set(reflect.ValueOf(exante.Timestamp{Time: t}))
+	} else {
+		v.Set(reflect.ValueOf(t))
+	}
+}
+
+func formatTime(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	switch layout {
+	case TimeUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	case TimeUnixMilli:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case "":
+		layout = time.RFC3339
+	}
+	return t.UTC().Format(layout)
+}
+
+func parseTime(s, layout string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	switch layout {
+	case TimeUnix, TimeUnixMilli:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if layout == TimeUnix {
+			return time.Unix(n, 0), nil
+		}
+		return time.UnixMilli(n), nil
+	case "":
+		layout = time.RFC3339
+	}
+	return time.Parse(layout, s)
+}
+
+func formatText(v reflect.Value, timeFormat string) string {
+	if t, ok := timeOf(v); ok {
+		return formatTime(t, timeFormat)
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, _ := m.MarshalText()
+		return string(text)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return ""
+		}
+		// A JSON array keeps items containing commas apart.
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(formatJSON(v, timeFormat)); err != nil {
+			return ""
+		}
+		return strings.TrimSuffix(buf.String(), "\n")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func parseText(v reflect.Value, s, timeFormat string) error {
+	if _, ok := timeOf(v); ok {
+		t, err := parseTime(s, timeFormat)
+		if err != nil {
+			return err
+		}
+		setTime(v, t)
+		return nil
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+		return nil
+	case reflect.Slice:
+		if s == "" {
+			return nil
+		}
+		dec := json.NewDecoder(strings.NewReader(s))
+		dec.UseNumber()
+		var items []interface{}
+		if err := dec.Decode(&items); err != nil {
+			return err
+		}
+		return parseJSON(v, items, timeFormat)
+	}
+	if s == "" {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		v.SetFloat(f)
+		return err
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		v.SetInt(n)
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, 64)
+		v.SetUint(n)
+		return err
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		v.SetBool(b)
+		return err
+	}
+	return fmt.Errorf("unsupported type %s", v.Type())
+}
+
+// formatJSON returns the JSON value of a column. Numbers and lists keep
+// their JSON types, Unix timestamps are numbers.
+func formatJSON(v reflect.Value, timeFormat string) interface{} {
+	if t, ok := timeOf(v); ok {
+		s := formatTime(t, timeFormat)
+		if s != "" && (timeFormat == TimeUnix || timeFormat == TimeUnixMilli) {
+			return json.Number(s)
+		}
+		return s
+	}
+	if _, ok := v.Interface().(encoding.TextMarshaler); ok {
+		return formatText(v, timeFormat)
+	}
+	if v.Kind() == reflect.Slice {
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = formatJSON(v.Index(i), timeFormat)
+		}
+		return items
+	}
+	return v.Interface()
+}
+
+func parseJSON(v reflect.Value, value interface{}, timeFormat string) error {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return parseText(v, value, timeFormat)
+	case json.Number:
+		return parseText(v, value.String(), timeFormat)
+	case bool:
+		return parseText(v, strconv.FormatBool(value), timeFormat)
+	case []interface{}:
+		if v.Kind() != reflect.Slice {
+			return fmt.Errorf("unexpected list for %s", v.Type())
+		}
+		slice := reflect.MakeSlice(v.Type(), len(value), len(value))
+		for i, item := range value {
+			if err := parseJSON(slice.Index(i), item, timeFormat); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+	return fmt.Errorf("unexpected value %v for %s", value, v.Type())
+}
+
+// orderedObject is a JSON object keeping the column order.
+type orderedObject []keyValue
+
+type keyValue struct {
+	key   string
+	value interface{}
+}
+
+// writeLine writes the object as a single line. Values are not HTML
+// escaped, unlike with json.Marshal.
+func (o orderedObject) writeLine(w io.Writer) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	buf.WriteByte('{')
+	for i, kv := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		// Encode appends a newline, which is dropped again.
+		if err := enc.Encode(kv.key); err != nil {
+			return err
+		}
+		buf.Truncate(buf.Len() - 1)
+		buf.WriteByte(':')
+		if err := enc.Encode(kv.value); err != nil {
+			return err
+		}
+		buf.Truncate(buf.Len() - 1)
+	}
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
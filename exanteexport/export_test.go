@@ -0,0 +1,140 @@
+package exanteexport
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zerodivisi0n/exante-api-go"
+)
+
+func testSymbols() []exante.Symbol {
+	option := exante.Symbol{
+		ID:         "AAPL.CBOE.17J2025.C200",
+		Ticker:     "AAPL",
+		Type:       exante.SymbolTypeOption,
+		Exchange:   "CBOE",
+		Currency:   "USD",
+		MPI:        0.01,
+		Expiration: exante.Timestamp{Time: time.Date(2025, 10, 17, 20, 0, 0, 0, time.UTC)},
+	}
+	option.OptionData.Right = exante.OptionCall
+	option.OptionData.StrikePrice = 200
+	return []exante.Symbol{
+		{ID: "AAPL.NASDAQ", Ticker: "AAPL", Name: "Apple", Description: "Apple, Inc.", Type: exante.SymbolTypeStock, Exchange: "NASDAQ", Currency: "USD", MPI: 0.01},
+		option,
+	}
+}
+
+func TestColumns(t *testing.T) {
+	columns, err := Columns[exante.Symbol]()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ID", "Name", "Description", "Ticker", "Type", "Exchange", "Country", "Currency", "MPI", "Group", "Expiration", "OptionData.Right", "OptionData.StrikePrice"}, columns)
+
+	columns, err = Columns[exante.SymbolScheduleInterval]()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Name", "Period.Start", "Period.End"}, columns)
+
+	_, err = Columns[string]()
+	assert.Error(t, err)
+}
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, CSV, testSymbols(), Options{
+		Columns: []string{"ID", "Type", "Expiration", "OptionData.Right", "OptionData.StrikePrice", "Description"},
+	}))
+	assert.Equal(t, "ID,Type,Expiration,OptionData.Right,OptionData.StrikePrice,Description\n"+
+		"AAPL.NASDAQ,STOCK,,,0,\"Apple, Inc.\"\n"+
+		"AAPL.CBOE.17J2025.C200,OPTION,2025-10-17T20:00:00Z,CALL,200,\n", buf.String())
+
+	symbols, err := Read[exante.Symbol](&buf, CSV, Options{})
+	require.NoError(t, err)
+	require.Len(t, symbols, 2)
+	assert.Equal(t, "Apple, Inc.", symbols[0].Description)
+	assert.True(t, symbols[0].Expiration.IsZero())
+	assert.Equal(t, exante.OptionCall, symbols[1].OptionData.Right)
+	assert.True(t, testSymbols()[1].Expiration.Equal(symbols[1].Expiration.Time))
+
+	_, err = NewWriter[exante.Symbol](&buf, CSV, Options{Columns: []string{"Strike"}})
+	assert.EqualError(t, err, `unknown column "Strike"`)
+}
+
+func TestJSONL(t *testing.T) {
+	groups := []exante.Group{
+		{Group: "ES", Name: "E-mini S&P 500", Types: []exante.SymbolType{exante.SymbolTypeFuture, exante.SymbolTypeOption}, Exchange: "CME"},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, JSONL, groups, Options{}))
+	assert.Equal(t, `{"Group":"ES","Name":"E-mini S&P 500","Types":["FUTURE","OPTION"],"Exchange":"CME"}`+"\n", buf.String())
+	read, err := Read[exante.Group](&buf, JSONL, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, groups, read)
+
+	buf.Reset()
+	require.NoError(t, Write(&buf, CSV, groups, Options{}))
+	read, err = Read[exante.Group](&buf, CSV, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, groups, read)
+
+	// List items containing commas round-trip through CSV
+	groups[0].Types = []exante.SymbolType{"A,B", exante.SymbolTypeOption}
+	buf.Reset()
+	require.NoError(t, Write(&buf, CSV, groups, Options{Columns: []string{"Group", "Types"}}))
+	assert.Equal(t, "Group,Types\nES,\"[\"\"A,B\"\",\"\"OPTION\"\"]\"\n", buf.String())
+	read, err = Read[exante.Group](&buf, CSV, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, groups[0].Types, read[0].Types)
+}
+
+func TestTimeFormat(t *testing.T) {
+	candles := []exante.OHLC{
+		{Timestamp: exante.Timestamp{Time: time.Unix(1704153600, 0)}, Open: 187.15, High: 188.44, Low: 183.89, Close: 185.64},
+	}
+	for _, tc := range []struct {
+		format     Format
+		timeFormat string
+		want       string
+	}{
+		{CSV, TimeUnixMilli, "Timestamp,Close\n1704153600000,185.64\n"},
+		{CSV, "2006-01-02 15:04", "Timestamp,Close\n2024-01-02 00:00,185.64\n"},
+		{JSONL, TimeUnix, `{"Timestamp":1704153600,"Close":185.64}` + "\n"},
+		{JSONL, "", `{"Timestamp":"2024-01-02T00:00:00Z","Close":185.64}` + "\n"},
+	} {
+		opts := Options{Columns: []string{"Timestamp", "Close"}, TimeFormat: tc.timeFormat}
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, tc.format, candles, opts))
+		assert.Equal(t, tc.want, buf.String())
+
+		read, err := Read[exante.OHLC](&buf, tc.format, opts)
+		require.NoError(t, err)
+		require.Len(t, read, 1)
+		assert.True(t, candles[0].Timestamp.Equal(read[0].Timestamp.Time), tc.want)
+		assert.Equal(t, candles[0].Close, read[0].Close)
+	}
+}
+
+func TestStreaming(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter[exante.SymbolSpecification](&buf, CSV, Options{})
+	require.NoError(t, err)
+	require.NoError(t, w.Flush())
+	assert.Equal(t, "Leverage,LotSize,ContractMultiplier,PriceUnit,Units\n", buf.String(), "header without records")
+
+	require.NoError(t, w.Write(exante.SymbolSpecification{Leverage: 0.2, LotSize: 1, ContractMultiplier: 1, PriceUnit: 1, Units: "Shares"}))
+	require.NoError(t, w.Write(exante.SymbolSpecification{Leverage: 0.1, LotSize: 100, Units: "Contracts"}))
+	require.NoError(t, w.Flush())
+
+	specs, err := Read[exante.SymbolSpecification](&buf, CSV, Options{})
+	require.NoError(t, err)
+	require.Len(t, specs, 2)
+	assert.Equal(t, "Contracts", specs[1].Units)
+	assert.Equal(t, 100.0, specs[1].LotSize)
+
+	_, err = Read[exante.SymbolSpecification](bytes.NewBufferString("Leverage,Margin\n1,2\n"), CSV, Options{})
+	assert.EqualError(t, err, `unknown column "Margin"`)
+	_, err = Read[exante.SymbolSpecification](bytes.NewBufferString("Leverage\nhigh\n"), CSV, Options{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,153 @@
+// Package exanteotel instruments exante API calls with OpenTelemetry spans.
+//
+// Spans are created through the global tracer provider unless another one is
+// supplied, so tracing is a no-op until a provider is configured. Use
+// Client.WithContext to parent the spans to the caller's trace.
+package exanteotel
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/zerodivisi0n/exante-api-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/zerodivisi0n/exante-api-go/exanteotel"
+
+type config struct {
+	provider    trace.TracerProvider
+	propagators propagation.TextMapPropagator
+}
+
+// Option configures the tracing middleware.
+type Option func(*config)
+
+// WithTracerProvider sets the provider used to create spans. The global
+// provider is used by default.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = tp
+	}
+}
+
+// WithPropagators sets the propagators used to inject trace context into
+// outgoing requests. The global propagators are used by default.
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagators = p
+	}
+}
+
+// Middleware returns a client middleware wrapping each API call in a span.
+func Middleware(opts ...Option) exante.Middleware {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return func(next exante.Handler) exante.Handler {
+		return func(call *exante.Call) (*http.Response, error) {
+			provider := cfg.provider
+			if provider == nil {
+				provider = otel.GetTracerProvider()
+			}
+			propagators := cfg.propagators
+			if propagators == nil {
+				propagators = otel.GetTextMapPropagator()
+			}
+
+			req := call.Request
+			ctx, span := provider.Tracer(instrumentationName).Start(req.Context(),
+				spanName(call),
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(callAttributes(call)...))
+			defer span.End()
+
+			call.Request = req.WithContext(ctx)
+			propagators.Inject(ctx, propagation.HeaderCarrier(call.Request.Header))
+
+			res, err := next(call)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return res, err
+			}
+			span.SetAttributes(attribute.Int("http.response.status_code", res.StatusCode))
+			if res.StatusCode >= 400 {
+				span.SetStatus(codes.Error, http.StatusText(res.StatusCode))
+				return res, nil
+			}
+			// Counting buffers the body, which is only worth it when the
+			// span is recorded.
+			if !span.IsRecording() {
+				return res, nil
+			}
+			if n, ok := countResults(res); ok {
+				span.SetAttributes(attribute.Int("exante.result.count", n))
+			}
+			return res, nil
+		}
+	}
+}
+
+func spanName(call *exante.Call) string {
+	endpoint := call.Template
+	if endpoint == "" {
+		endpoint = call.Endpoint
+	}
+	return call.Request.Method + " " + endpoint
+}
+
+func callAttributes(call *exante.Call) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String("http.request.method", call.Request.Method),
+		attribute.String("url.full", call.Request.URL.String()),
+		attribute.String("server.address", call.Request.URL.Hostname()),
+		attribute.String("exante.scope", call.Scope),
+		attribute.String("exante.endpoint", call.Template),
+	}
+	if call.Attempt > 0 {
+		attrs = append(attrs, attribute.Int("http.request.resend_count", call.Attempt))
+	}
+	// Only symbol and OHLC endpoints take a symbol ID, the others
+	// address exchanges, types or groups.
+	if id, ok := call.PathParams["id"]; ok &&
+		(strings.HasPrefix(call.Template, "/symbols/") || strings.HasPrefix(call.Template, "/ohlc/")) {
+		attrs = append(attrs, attribute.String("exante.symbol.id", id))
+	}
+	return attrs
+}
+
+// countResults peeks into a JSON array response and counts its elements.
+// The body is restored so it can still be decoded by the client.
+func countResults(res *http.Response) (int, bool) {
+	if res.Body == nil {
+		return 0, false
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return 0, false
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return 0, false
+	}
+	n := 0
+	for dec.More() {
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return 0, false
+		}
+		n++
+	}
+	return n, true
+}
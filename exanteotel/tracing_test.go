@@ -0,0 +1,121 @@
+package exanteotel
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zerodivisi0n/exante-api-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gopkg.in/h2non/gock.v1"
+)
+
+const baseUrl = "https://api-demo.exante.eu/md/1.0"
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	res := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		res[kv.Key] = kv.Value
+	}
+	return res
+}
+
+func TestMiddleware(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/ohlc/AAPL.NASDAQ/60").
+		MatchHeader("Traceparent", "^00-[0-9a-f]{32}-[0-9a-f]{16}-01$").
+		Reply(200).
+		BodyString(`[
+			{"timestamp":1493251200000,"open":1,"high":2,"low":0.5,"close":1.5},
+			{"timestamp":1493251260000,"open":1.5,"high":2,"low":1,"close":1}
+	]`)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	client := exante.NewClient("", "", "", exante.WithMiddleware(
+		Middleware(WithTracerProvider(provider), WithPropagators(propagation.TraceContext{}))))
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	candles, err := client.WithContext(ctx).OHLC("AAPL.NASDAQ", exante.Duration1Minute,
+		time.Unix(1493251200, 0), time.Unix(1493251260, 0), 2)
+	parent.End()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(candles), "Invalid candles length")
+
+	spans := recorder.Ended()
+	assert.Equal(t, 2, len(spans), "Invalid spans length")
+	span := spans[0]
+	assert.Equal(t, "GET /ohlc/{id}/{duration}", span.Name())
+	assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	attrs := spanAttributes(span)
+	assert.Equal(t, "GET", attrs["http.request.method"].AsString())
+	assert.Equal(t, "api-demo.exante.eu", attrs["server.address"].AsString())
+	assert.Equal(t, int64(200), attrs["http.response.status_code"].AsInt64())
+	assert.Equal(t, "ohlc", attrs["exante.scope"].AsString())
+	assert.Equal(t, "AAPL.NASDAQ", attrs["exante.symbol.id"].AsString())
+	assert.Equal(t, int64(2), attrs["exante.result.count"].AsInt64())
+}
+
+func TestMiddlewareError(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/groups/UNKNOWN").
+		Reply(404).
+		BodyString(`{"message":"not found"}`)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	client := exante.NewClient("", "", "", exante.WithMiddleware(
+		Middleware(WithTracerProvider(provider))))
+
+	_, err := client.GroupSymbols("UNKNOWN")
+
+	assert.Error(t, err)
+	spans := recorder.Ended()
+	assert.Equal(t, 1, len(spans), "Invalid spans length")
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	attrs := spanAttributes(spans[0])
+	assert.Equal(t, int64(404), attrs["http.response.status_code"].AsInt64())
+	assert.NotContains(t, attrs, attribute.Key("exante.symbol.id"))
+	assert.NotContains(t, attrs, attribute.Key("exante.result.count"))
+}
+
+func TestMiddlewareNoop(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/types").
+		Reply(200).
+		BodyString(`[{"id": "STOCK"}]`)
+
+	types, err := exante.NewClient("", "", "", exante.WithMiddleware(Middleware())).Types()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []exante.SymbolType{exante.SymbolTypeStock}, types)
+	assert.Empty(t, gock.GetUnmatchedRequests())
+
+	// The body of unrecorded spans is passed through unread.
+	body := io.NopCloser(strings.NewReader(`[]`))
+	handler := Middleware()(func(call *exante.Call) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: body, Request: call.Request}, nil
+	})
+	req, _ := http.NewRequest(http.MethodGet, baseUrl+"/types", nil)
+	res, err := handler(&exante.Call{Request: req})
+	assert.NoError(t, err)
+	assert.Equal(t, body, res.Body)
+}
@@ -0,0 +1,206 @@
+// Package exanteparquet stores symbols and candles in Parquet files.
+//
+// Timestamps are INT64 milliseconds with the TIMESTAMP logical type, option
+// data is flattened into optional columns and low cardinality strings such
+// as exchange and currency are dictionary encoded.
+package exanteparquet
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/zerodivisi0n/exante-api-go"
+)
+
+// SymbolRow is the Parquet schema of a symbol.
+type SymbolRow struct {
+	ID          string  `parquet:"id"`
+	Ticker      string  `parquet:"ticker"`
+	Name        string  `parquet:"name"`
+	Description string  `parquet:"description"`
+	Type        string  `parquet:"type,dict"`
+	Exchange    string  `parquet:"exchange,dict"`
+	Country     string  `parquet:"country,dict"`
+	Currency    string  `parquet:"currency,dict"`
+	MPI         float64 `parquet:"mpi"`
+	Group       string  `parquet:"group,dict"`
+	// Zero values of optional columns are stored as nulls.
+	Expiration  int64   `parquet:"expiration,optional,timestamp(millisecond)"`
+	OptionRight string  `parquet:"option_right,optional,dict"`
+	StrikePrice float64 `parquet:"strike_price,optional"`
+}
+
+// OHLCRow is the Parquet schema of a candle.
+type OHLCRow struct {
+	Timestamp int64   `parquet:"timestamp,timestamp(millisecond)"`
+	Open      float64 `parquet:"open"`
+	High      float64 `parquet:"high"`
+	Low       float64 `parquet:"low"`
+	Close     float64 `parquet:"close"`
+}
+
+func NewSymbolRow(s *exante.Symbol) SymbolRow {
+	row := SymbolRow{
+		ID:          s.ID,
+		Ticker:      s.Ticker,
+		Name:        s.Name,
+		Description: s.Description,
+		Type:        string(s.Type),
+		Exchange:    s.Exchange,
+		Country:     s.Country,
+		Currency:    s.Currency,
+		MPI:         s.MPI,
+		Group:       s.Group,
+	}
+	if !s.Expiration.IsZero() {
+		row.Expiration = s.Expiration.UnixMilli()
+	}
+	if s.OptionData.Right != "" {
+		row.OptionRight = string(s.OptionData.Right)
+		row.StrikePrice = s.OptionData.StrikePrice
+	}
+	return row
+}
+
+func (row *SymbolRow) Symbol() exante.Symbol {
+	s := exante.Symbol{
+		ID:          row.ID,
+		Ticker:      row.Ticker,
+		Name:        row.Name,
+		Description: row.Description,
+		Type:        exante.SymbolType(row.Type),
+		Exchange:    row.Exchange,
+		Country:     row.Country,
+		Currency:    row.Currency,
+		MPI:         row.MPI,
+		Group:       row.Group,
+	}
+	if row.Expiration != 0 {
+		s.Expiration = exante.Timestamp{Time: time.UnixMilli(row.Expiration)}
+	}
+	s.OptionData.Right = exante.OptionRight(row.OptionRight)
+	s.OptionData.StrikePrice = row.StrikePrice
+	return s
+}
+
+func NewOHLCRow(c *exante.OHLC) OHLCRow {
+	return OHLCRow{
+		Timestamp: c.Timestamp.UnixMilli(),
+		Open:      c.Open,
+		High:      c.High,
+		Low:       c.Low,
+		Close:     c.Close,
+	}
+}
+
+func (row *OHLCRow) OHLC() exante.OHLC {
+	return exante.OHLC{
+		Timestamp: exante.Timestamp{Time: time.UnixMilli(row.Timestamp)},
+		Open:      row.Open,
+		High:      row.High,
+		Low:       row.Low,
+		Close:     row.Close,
+	}
+}
+
+// WriteSymbols writes symbols as a Parquet file to w.
+func WriteSymbols(w io.Writer, symbols []exante.Symbol) error {
+	rows := make([]SymbolRow, len(symbols))
+	for i := range symbols {
+		rows[i] = NewSymbolRow(&symbols[i])
+	}
+	return parquet.Write(w, rows)
+}
+
+// WriteOHLC writes candles as a Parquet file to w.
+func WriteOHLC(w io.Writer, candles []exante.OHLC) error {
+	ow := NewOHLCWriter(w)
+	if err := ow.Write(candles...); err != nil {
+		return err
+	}
+	return ow.Close()
+}
+
+// OHLCWriter streams candles to a Parquet file, e.g. page by page as they
+// are downloaded. The file is complete once Close returns.
+type OHLCWriter struct {
+	w *parquet.GenericWriter[OHLCRow]
+}
+
+func NewOHLCWriter(w io.Writer) *OHLCWriter {
+	return &OHLCWriter{w: parquet.NewGenericWriter[OHLCRow](w)}
+}
+
+func (ow *OHLCWriter) Write(candles ...exante.OHLC) error {
+	rows := make([]OHLCRow, len(candles))
+	for i := range candles {
+		rows[i] = NewOHLCRow(&candles[i])
+	}
+	_, err := ow.w.Write(rows)
+	return err
+}
+
+// Close flushes buffered rows and writes the file footer. It does not close
+// the underlying writer.
+func (ow *OHLCWriter) Close() error {
+	return ow.w.Close()
+}
+
+// ReadSymbols reads a file written by WriteSymbols.
+func ReadSymbols(r io.ReaderAt, size int64) ([]exante.Symbol, error) {
+	rows, err := parquet.Read[SymbolRow](r, size)
+	if err != nil {
+		return nil, err
+	}
+	symbols := make([]exante.Symbol, len(rows))
+	for i := range rows {
+		symbols[i] = rows[i].Symbol()
+	}
+	return symbols, nil
+}
+
+// ReadOHLC reads a file written by WriteOHLC or an OHLCWriter.
+func ReadOHLC(r io.ReaderAt, size int64) ([]exante.OHLC, error) {
+	rows, err := parquet.Read[OHLCRow](r, size)
+	if err != nil {
+		return nil, err
+	}
+	candles := make([]exante.OHLC, len(rows))
+	for i := range rows {
+		candles[i] = rows[i].OHLC()
+	}
+	return candles, nil
+}
+
+func ReadSymbolsFile(path string) ([]exante.Symbol, error) {
+	f, size, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadSymbols(f, size)
+}
+
+func ReadOHLCFile(path string) ([]exante.OHLC, error) {
+	f, size, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadOHLC(f, size)
+}
+
+func open(path string) (*os.File, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return f, info.Size(), nil
+}
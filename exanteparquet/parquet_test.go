@@ -0,0 +1,86 @@
+package exanteparquet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zerodivisi0n/exante-api-go"
+)
+
+func TestSymbols(t *testing.T) {
+	option := exante.Symbol{
+		ID:         "AAPL.CBOE.17J2025.C200",
+		Ticker:     "AAPL",
+		Type:       exante.SymbolTypeOption,
+		Exchange:   "CBOE",
+		Currency:   "USD",
+		MPI:        0.01,
+		Group:      "AAPL.CBOE",
+		Expiration: exante.Timestamp{Time: time.Date(2025, 10, 17, 20, 0, 0, 0, time.UTC)},
+	}
+	option.OptionData.Right = exante.OptionCall
+	option.OptionData.StrikePrice = 200
+	symbols := []exante.Symbol{
+		{ID: "AAPL.NASDAQ", Ticker: "AAPL", Name: "Apple", Type: exante.SymbolTypeStock, Exchange: "NASDAQ", Country: "US", Currency: "USD", MPI: 0.01},
+		option,
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSymbols(&buf, symbols))
+	read, err := ReadSymbols(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, read, 2)
+	assert.Equal(t, symbols[0], read[0])
+	assert.True(t, option.Expiration.Equal(read[1].Expiration.Time))
+	assert.Equal(t, option.OptionData, read[1].OptionData)
+	assert.True(t, read[0].Expiration.IsZero())
+
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	schema := f.Schema()
+	exchange, _ := schema.Lookup("exchange")
+	assert.Equal(t, "RLE_DICTIONARY", exchange.Node.Encoding().String())
+	expiration, _ := schema.Lookup("expiration")
+	assert.True(t, expiration.Node.Optional())
+	assert.Equal(t, parquet.Int64, expiration.Node.Type().Kind())
+}
+
+func TestOHLC(t *testing.T) {
+	start := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	var candles []exante.OHLC
+	for i := 0; i < 100; i++ {
+		candles = append(candles, exante.OHLC{
+			Timestamp: exante.Timestamp{Time: start.Add(time.Duration(i) * time.Minute)},
+			Open:      100 + float64(i)/100,
+			High:      101,
+			Low:       99.5,
+			Close:     100.25,
+		})
+	}
+
+	var buf bytes.Buffer
+	w := NewOHLCWriter(&buf)
+	require.NoError(t, w.Write(candles[:60]...))
+	require.NoError(t, w.Write(candles[60:]...))
+	require.NoError(t, w.Close())
+
+	read, err := ReadOHLC(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, read, len(candles))
+	for i := range candles {
+		assert.True(t, candles[i].Timestamp.Equal(read[i].Timestamp.Time))
+		assert.Equal(t, candles[i].Open, read[i].Open)
+		assert.Equal(t, candles[i].Close, read[i].Close)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	ts, _ := f.Schema().Lookup("timestamp")
+	logical := ts.Node.Type().LogicalType()
+	require.NotNil(t, logical.Timestamp)
+	assert.NotNil(t, logical.Timestamp.Unit.Millis)
+}
@@ -0,0 +1,110 @@
+// Package exanteprom exports Prometheus metrics for exante API calls.
+package exanteprom
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zerodivisi0n/exante-api-go"
+)
+
+const namespace = "exante"
+
+// Collector records API traffic. Metrics are labelled by endpoint template
+// (e.g. "/symbols/{id}") and JWT scope to keep cardinality bounded.
+type Collector struct {
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+	retries  *prometheus.CounterVec
+}
+
+var _ prometheus.Collector = (*Collector)(nil)
+
+// NewCollector creates a Collector and registers its metrics with reg. The
+// metrics are registered together, so a failed registration leaves reg
+// unchanged.
+func NewCollector(reg prometheus.Registerer) (*Collector, error) {
+	labels := []string{"endpoint", "scope"}
+	c := &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Number of API requests sent, retries included.",
+		}, labels),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "API request latency.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "request_errors_total",
+			Help:      "Number of failed API requests by status class (4xx, 5xx or transport).",
+		}, append(labels, "class")),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "request_retries_total",
+			Help:      "Number of retried API requests.",
+		}, labels),
+	}
+	if err := reg.Register(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Collector) metrics() []prometheus.Collector {
+	return []prometheus.Collector{c.requests, c.latency, c.errors, c.retries}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.metrics() {
+		m.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range c.metrics() {
+		m.Collect(ch)
+	}
+}
+
+// Middleware returns a client middleware feeding the collector, to be
+// passed to exante.WithMiddleware.
+func (c *Collector) Middleware() exante.Middleware {
+	return func(next exante.Handler) exante.Handler {
+		return func(call *exante.Call) (*http.Response, error) {
+			endpoint := call.Template
+			if endpoint == "" {
+				endpoint = call.Endpoint
+			}
+			c.requests.WithLabelValues(endpoint, call.Scope).Inc()
+			if call.Attempt > 0 {
+				c.retries.WithLabelValues(endpoint, call.Scope).Inc()
+			}
+			start := time.Now()
+			res, err := next(call)
+			c.latency.WithLabelValues(endpoint, call.Scope).Observe(time.Since(start).Seconds())
+			if class := errorClass(res, err); class != "" {
+				c.errors.WithLabelValues(endpoint, call.Scope, class).Inc()
+			}
+			return res, err
+		}
+	}
+}
+
+func errorClass(res *http.Response, err error) string {
+	if err != nil {
+		return "transport"
+	}
+	if res.StatusCode < 400 {
+		return ""
+	}
+	return strconv.Itoa(res.StatusCode/100) + "xx"
+}
@@ -0,0 +1,85 @@
+package exanteprom
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/zerodivisi0n/exante-api-go"
+)
+
+func call(endpoint, template string, attempt int) *exante.Call {
+	req, _ := http.NewRequest("GET", "https://example.com"+endpoint, nil)
+	return &exante.Call{
+		Endpoint: endpoint,
+		Template: template,
+		Scope:    "symbols",
+		Attempt:  attempt,
+		Request:  req,
+	}
+}
+
+func reply(status int, err error) exante.Handler {
+	return func(*exante.Call) (*http.Response, error) {
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{StatusCode: status}, nil
+	}
+}
+
+func TestCollector(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	c, err := NewCollector(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mw := c.Middleware()
+
+	mw(reply(200, nil))(call("/symbols/AAPL.NASDAQ", "/symbols/{id}", 0))
+	mw(reply(401, nil))(call("/symbols/GOOG.NASDAQ", "/symbols/{id}", 0))
+	mw(reply(200, nil))(call("/symbols/GOOG.NASDAQ", "/symbols/{id}", 1))
+	mw(reply(503, nil))(call("/exchanges", "/exchanges", 0))
+	mw(reply(0, errors.New("timeout")))(call("/exchanges", "/exchanges", 0))
+
+	assert.Equal(t, 3.0, testutil.ToFloat64(c.requests.WithLabelValues("/symbols/{id}", "symbols")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.requests.WithLabelValues("/exchanges", "symbols")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.retries.WithLabelValues("/symbols/{id}", "symbols")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.errors.WithLabelValues("/symbols/{id}", "symbols", "4xx")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.errors.WithLabelValues("/exchanges", "symbols", "5xx")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.errors.WithLabelValues("/exchanges", "symbols", "transport")))
+
+	err = testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP exante_request_retries_total Number of retried API requests.
+# TYPE exante_request_retries_total counter
+exante_request_retries_total{endpoint="/symbols/{id}",scope="symbols"} 1
+`), "exante_request_retries_total")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, testutil.CollectAndCount(c.latency))
+}
+
+func TestCollectorDuplicateRegistration(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	if _, err := NewCollector(reg); err != nil {
+		t.Fatal(err)
+	}
+	_, err := NewCollector(reg)
+	assert.Error(t, err)
+
+	// A conflict leaves nothing behind that breaks a retry.
+	reg = prometheus.NewRegistry()
+	conflict := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "exante_request_retries_total",
+		Help: "Number of retried API requests.",
+	}, []string{"endpoint", "scope"})
+	reg.MustRegister(conflict)
+	_, err = NewCollector(reg)
+	assert.Error(t, err)
+	reg.Unregister(conflict)
+	_, err = NewCollector(reg)
+	assert.NoError(t, err)
+}
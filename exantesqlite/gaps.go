@@ -0,0 +1,130 @@
+package exantesqlite
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zerodivisi0n/exante-api-go"
+)
+
+// Gap is a run of consecutive expected bars missing from the store.
+type Gap struct {
+	Start time.Time // first missing bar
+	End   time.Time // end of the last missing bar
+	Bars  int
+	// Err is the error of the last attempt to fetch the gap. Gaps without
+	// one were not returned by the API, e.g. as no trades happened.
+	Err error
+}
+
+type GapOptions struct {
+	// Range of bar start times to check, To defaults to now
+	From time.Time
+	To   time.Time
+	// Additional fetches of gaps still missing after the first one
+	Retries int
+	// Candles per request, exante.DefaultDownloadPageSize when zero
+	PageSize int
+}
+
+type GapReport struct {
+	Expected int   // bars expected from the trading schedule
+	Fetched  int   // candles fetched and stored
+	Gaps     []Gap // bars still missing after all retries
+}
+
+// SyncGaps compares the stored candles of a symbol with the bars expected
+// from its trading schedule and fetches only the missing ranges, which
+// include new bars since the last sync. The schedule is fetched and stored
+// first; bars outside of the stored schedules are not checked. Failed
+// fetches do not stop the sync but are reported with the remaining gaps.
+func (s *Store) SyncGaps(ctx context.Context, source exante.MarketData, id string, duration exante.Duration, opts GapOptions) (*GapReport, error) {
+	if opts.From.IsZero() {
+		return nil, errors.New("GapOptions.From is required")
+	}
+	if opts.To.IsZero() {
+		opts.To = s.now()
+	}
+	if opts.PageSize == 0 {
+		opts.PageSize = exante.DefaultDownloadPageSize
+	}
+	if opts.PageSize < 0 || opts.Retries < 0 || duration <= 0 {
+		return nil, errors.New("gap sync needs a positive duration and page size and non-negative retries")
+	}
+	intervals, err := source.SymbolSchedule(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.SaveSchedule(ctx, id, intervals); err != nil {
+		return nil, err
+	}
+	if intervals, err = s.Schedule(ctx, id, opts.From, opts.To); err != nil {
+		return nil, err
+	}
+	expected := exante.NewSchedule(intervals).Bars(duration, opts.From, opts.To)
+	report := &GapReport{Expected: len(expected)}
+
+	var failed []Gap // fetches of the last attempt that failed
+	for attempt := 0; ; attempt++ {
+		gaps, err := s.gaps(ctx, id, duration, expected)
+		if err != nil {
+			return report, err
+		}
+		if len(gaps) == 0 || attempt > opts.Retries {
+			for i := range gaps {
+				for _, f := range failed {
+					if !gaps[i].Start.Before(f.Start) && gaps[i].Start.Before(f.End) {
+						gaps[i].Err = f.Err
+					}
+				}
+			}
+			report.Gaps = gaps
+			return report, nil
+		}
+		failed = failed[:0]
+		for _, gap := range gaps {
+			n, err := s.syncRange(ctx, source, id, duration, gap.Start, gap.End, opts.PageSize)
+			report.Fetched += n
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return report, ctxErr
+			}
+			if err != nil {
+				gap.Err = err
+				failed = append(failed, gap)
+			}
+		}
+	}
+}
+
+// gaps groups the expected bars missing from the store into runs of
+// consecutive bars.
+func (s *Store) gaps(ctx context.Context, id string, duration exante.Duration, expected []time.Time) ([]Gap, error) {
+	if len(expected) == 0 {
+		return nil, nil
+	}
+	d := time.Duration(duration) * time.Second
+	candles, err := s.OHLC(ctx, id, duration, expected[0], expected[len(expected)-1].Add(d))
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[int64]bool, len(candles))
+	for _, c := range candles {
+		stored[c.Timestamp.Unix()] = true
+	}
+	var gaps []Gap
+	for _, bar := range expected {
+		if stored[bar.Unix()] {
+			continue
+		}
+		// Bars continue a gap only when adjacent in time, so that a gap
+		// never spans a closed session.
+		if n := len(gaps); n > 0 && gaps[n-1].End.Equal(bar) {
+			gaps[n-1].End = bar.Add(d)
+			gaps[n-1].Bars++
+		} else {
+			gaps = append(gaps, Gap{Start: bar, End: bar.Add(d), Bars: 1})
+		}
+	}
+	return gaps, nil
+}
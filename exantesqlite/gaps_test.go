@@ -0,0 +1,114 @@
+package exantesqlite
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zerodivisi0n/exante-api-go"
+	"github.com/zerodivisi0n/exante-api-go/exantetest"
+)
+
+// weekSchedule is a trading week starting on Monday day0, followed by the
+// weekend and the next Monday.
+func weekSchedule() []exante.SymbolScheduleInterval {
+	var intervals []exante.SymbolScheduleInterval
+	add := func(name exante.SessionName, start, end time.Time) {
+		var in exante.SymbolScheduleInterval
+		in.Name = name
+		in.Period.Start = exante.Timestamp{Time: start}
+		in.Period.End = exante.Timestamp{Time: end}
+		intervals = append(intervals, in)
+	}
+	for _, day := range []int{0, 1, 2, 3, 4, 7} {
+		open := day0.AddDate(0, 0, day).Add(14*time.Hour + 30*time.Minute)
+		add(exante.SessionMain, open, open.Add(6*time.Hour+30*time.Minute))
+	}
+	add(exante.SessionClosed, day0.AddDate(0, 0, 4).Add(21*time.Hour), day0.AddDate(0, 0, 7).Add(14*time.Hour+30*time.Minute))
+	return intervals
+}
+
+func gapServer(t *testing.T) *exantetest.Server {
+	srv := exantetest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.SetSchedule("AAPL.NASDAQ", weekSchedule())
+	// No trades on Wednesday
+	for _, day := range []int{0, 1, 3, 4, 7} {
+		srv.AddOHLC("AAPL.NASDAQ", exante.Duration1Day, candle(day, float64(day)))
+	}
+	return srv
+}
+
+func TestSyncGaps(t *testing.T) {
+	ctx := context.Background()
+	srv := gapServer(t)
+	s := openStore(t)
+	require.NoError(t, s.SaveOHLC(ctx, "AAPL.NASDAQ", exante.Duration1Day, []exante.OHLC{candle(0, 0)}))
+
+	opts := GapOptions{From: day0, To: day0.AddDate(0, 0, 8), Retries: 1}
+	report, err := s.SyncGaps(ctx, srv.Client(), "AAPL.NASDAQ", exante.Duration1Day, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 6, report.Expected, "weekend skipped")
+	assert.Equal(t, 4, report.Fetched)
+	require.Len(t, report.Gaps, 1)
+	gap := report.Gaps[0]
+	assert.True(t, gap.Start.Equal(day0.AddDate(0, 0, 2)))
+	assert.True(t, gap.End.Equal(day0.AddDate(0, 0, 3)))
+	assert.Equal(t, 1, gap.Bars)
+	assert.NoError(t, gap.Err)
+	// The schedule, fetches of Tuesday to Friday and of Monday without the
+	// weekend, and one retry of Wednesday.
+	assert.Equal(t, []string{
+		"/symbols/AAPL.NASDAQ/schedule",
+		"/ohlc/AAPL.NASDAQ/86400",
+		"/ohlc/AAPL.NASDAQ/86400",
+		"/ohlc/AAPL.NASDAQ/86400",
+	}, srv.Requests())
+
+	stored, err := s.Schedule(ctx, "AAPL.NASDAQ", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, stored, 7)
+}
+
+func TestSyncGapsFailures(t *testing.T) {
+	ctx := context.Background()
+	srv := gapServer(t)
+	s := openStore(t)
+	opts := GapOptions{From: day0, To: day0.AddDate(0, 0, 8), Retries: 1}
+
+	srv.InjectFault(exantetest.Fault{Path: "/ohlc/", Times: 4, Status: http.StatusBadGateway})
+	report, err := s.SyncGaps(ctx, srv.Client(), "AAPL.NASDAQ", exante.Duration1Day, opts)
+	require.NoError(t, err)
+	assert.Zero(t, report.Fetched)
+	require.Len(t, report.Gaps, 2, "split at the weekend")
+	assert.Equal(t, 5, report.Gaps[0].Bars)
+	assert.True(t, report.Gaps[0].End.Equal(day0.AddDate(0, 0, 5)))
+	assert.Error(t, report.Gaps[0].Err)
+	assert.Equal(t, 1, report.Gaps[1].Bars)
+	assert.True(t, report.Gaps[1].Start.Equal(day0.AddDate(0, 0, 7)))
+	assert.Error(t, report.Gaps[1].Err)
+
+	report, err = s.SyncGaps(ctx, srv.Client(), "AAPL.NASDAQ", exante.Duration1Day, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 5, report.Fetched)
+	require.Len(t, report.Gaps, 1)
+	assert.NoError(t, report.Gaps[0].Err)
+
+	// Nothing is fetched when the store is complete up to To.
+	requests := len(srv.Requests())
+	opts.To = day0.AddDate(0, 0, 2)
+	report, err = s.SyncGaps(ctx, srv.Client(), "AAPL.NASDAQ", exante.Duration1Day, opts)
+	require.NoError(t, err)
+	assert.Empty(t, report.Gaps)
+	assert.Len(t, srv.Requests(), requests+1, "only the schedule")
+
+	opts.PageSize = -1
+	_, err = s.SyncGaps(ctx, srv.Client(), "AAPL.NASDAQ", exante.Duration1Day, opts)
+	assert.Error(t, err)
+	opts.PageSize, opts.Retries = 0, -1
+	_, err = s.SyncGaps(ctx, srv.Client(), "AAPL.NASDAQ", exante.Duration1Day, opts)
+	assert.Error(t, err)
+}
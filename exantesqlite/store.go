@@ -0,0 +1,421 @@
+// Package exantesqlite keeps a local SQLite copy of symbols, specifications,
+// schedules and candles. It uses a pure Go driver, so no C toolchain is
+// needed.
+package exantesqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/zerodivisi0n/exante-api-go"
+	_ "modernc.org/sqlite"
+)
+
+// schemaVersion is stored in PRAGMA user_version.
+const schemaVersion = 1
+
+const schema = `
+CREATE TABLE IF NOT EXISTS symbols (
+	id           TEXT PRIMARY KEY,
+	name         TEXT NOT NULL,
+	description  TEXT NOT NULL,
+	ticker       TEXT NOT NULL,
+	type         TEXT NOT NULL,
+	exchange     TEXT NOT NULL,
+	country      TEXT NOT NULL,
+	currency     TEXT NOT NULL,
+	mpi          REAL NOT NULL,
+	group_id     TEXT NOT NULL,
+	expiration   INTEGER,
+	option_right TEXT,
+	strike_price REAL,
+	updated_at   INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS symbols_exchange ON symbols (exchange);
+CREATE INDEX IF NOT EXISTS symbols_group ON symbols (group_id);
+
+CREATE TABLE IF NOT EXISTS specifications (
+	symbol_id           TEXT PRIMARY KEY,
+	leverage            REAL NOT NULL,
+	lot_size            REAL NOT NULL,
+	contract_multiplier REAL NOT NULL,
+	price_unit          REAL NOT NULL,
+	units               TEXT NOT NULL,
+	updated_at          INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS schedules (
+	symbol_id TEXT NOT NULL,
+	start     INTEGER NOT NULL,
+	end       INTEGER NOT NULL,
+	name      TEXT NOT NULL,
+	PRIMARY KEY (symbol_id, start)
+) WITHOUT ROWID;
+
+CREATE TABLE IF NOT EXISTS ohlc (
+	symbol_id TEXT NOT NULL,
+	duration  INTEGER NOT NULL,
+	timestamp INTEGER NOT NULL,
+	open      REAL NOT NULL,
+	high      REAL NOT NULL,
+	low       REAL NOT NULL,
+	close     REAL NOT NULL,
+	PRIMARY KEY (symbol_id, duration, timestamp)
+) WITHOUT ROWID;
+
+CREATE TABLE IF NOT EXISTS ohlc_sync (
+	symbol_id TEXT NOT NULL,
+	duration  INTEGER NOT NULL,
+	last      INTEGER NOT NULL,
+	synced_at INTEGER NOT NULL,
+	PRIMARY KEY (symbol_id, duration)
+) WITHOUT ROWID;
+`
+
+// Store is a SQLite database of market data. Timestamps are stored as Unix
+// milliseconds, as in the API.
+type Store struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// Open opens or creates the database at path and migrates its schema.
+func Open(path string) (*Store, error) {
+	dsn := url.URL{
+		Scheme:   "file",
+		Opaque:   url.PathEscape(path),
+		RawQuery: "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)",
+	}
+	db, err := sql.Open("sqlite", dsn.String())
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{db: db, now: time.Now}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version > schemaVersion {
+		return fmt.Errorf("database schema version %d is newer than supported %d", version, schemaVersion)
+	}
+	if _, err := s.db.Exec(schema); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(fmt.Sprintf("PRAGMA user_version = %d", schemaVersion))
+	return err
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// DB returns the underlying database for custom queries.
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+// SaveSymbols inserts or updates symbols.
+func (s *Store) SaveSymbols(ctx context.Context, symbols []exante.Symbol) error {
+	return s.saveSymbols(ctx, symbols, false)
+}
+
+// ReplaceSymbols stores symbols and deletes all others in one transaction.
+func (s *Store) ReplaceSymbols(ctx context.Context, symbols []exante.Symbol) error {
+	return s.saveSymbols(ctx, symbols, true)
+}
+
+func (s *Store) saveSymbols(ctx context.Context, symbols []exante.Symbol, replace bool) error {
+	now := s.now().UnixMilli()
+	return s.tx(ctx, `
+		INSERT INTO symbols (id, name, description, ticker, type, exchange, country, currency, mpi, group_id, expiration, option_right, strike_price, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			name = excluded.name, description = excluded.description, ticker = excluded.ticker,
+			type = excluded.type, exchange = excluded.exchange, country = excluded.country,
+			currency = excluded.currency, mpi = excluded.mpi, group_id = excluded.group_id,
+			expiration = excluded.expiration, option_right = excluded.option_right,
+			strike_price = excluded.strike_price, updated_at = excluded.updated_at`,
+		func(tx *sql.Tx, stmt *sql.Stmt) error {
+			if replace {
+				if _, err := tx.ExecContext(ctx, `DELETE FROM symbols`); err != nil {
+					return err
+				}
+			}
+			for _, sym := range symbols {
+				var (
+					expiration  sql.NullInt64
+					right       sql.NullString
+					strikePrice sql.NullFloat64
+				)
+				if !sym.Expiration.IsZero() {
+					expiration = sql.NullInt64{Int64: sym.Expiration.UnixMilli(), Valid: true}
+				}
+				if sym.OptionData.Right != "" {
+					right = sql.NullString{String: string(sym.OptionData.Right), Valid: true}
+					strikePrice = sql.NullFloat64{Float64: sym.OptionData.StrikePrice, Valid: true}
+				}
+				if _, err := stmt.ExecContext(ctx, sym.ID, sym.Name, sym.Description, sym.Ticker, string(sym.Type),
+					sym.Exchange, sym.Country, sym.Currency, sym.MPI, sym.Group,
+					expiration, right, strikePrice, now); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+}
+
+const symbolColumns = `id, name, description, ticker, type, exchange, country, currency, mpi, group_id, expiration, option_right, strike_price`
+
+// Symbols returns the stored symbols ordered by ID.
+func (s *Store) Symbols(ctx context.Context) ([]exante.Symbol, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+symbolColumns+` FROM symbols ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var symbols []exante.Symbol
+	for rows.Next() {
+		sym, err := scanSymbol(rows)
+		if err != nil {
+			return nil, err
+		}
+		symbols = append(symbols, *sym)
+	}
+	return symbols, rows.Err()
+}
+
+// Symbol returns a stored symbol, or sql.ErrNoRows.
+func (s *Store) Symbol(ctx context.Context, id string) (*exante.Symbol, error) {
+	return scanSymbol(s.db.QueryRowContext(ctx, `SELECT `+symbolColumns+` FROM symbols WHERE id = ?`, id))
+}
+
+func scanSymbol(row interface{ Scan(...interface{}) error }) (*exante.Symbol, error) {
+	var (
+		sym         exante.Symbol
+		expiration  sql.NullInt64
+		right       sql.NullString
+		strikePrice sql.NullFloat64
+	)
+	if err := row.Scan(&sym.ID, &sym.Name, &sym.Description, &sym.Ticker, &sym.Type, &sym.Exchange,
+		&sym.Country, &sym.Currency, &sym.MPI, &sym.Group, &expiration, &right, &strikePrice); err != nil {
+		return nil, err
+	}
+	if expiration.Valid {
+		sym.Expiration = exante.Timestamp{Time: time.UnixMilli(expiration.Int64)}
+	}
+	sym.OptionData.Right = exante.OptionRight(right.String)
+	sym.OptionData.StrikePrice = strikePrice.Float64
+	return &sym, nil
+}
+
+// SaveSpecification inserts or updates the specification of a symbol.
+func (s *Store) SaveSpecification(ctx context.Context, id string, spec *exante.SymbolSpecification) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO specifications (symbol_id, leverage, lot_size, contract_multiplier, price_unit, units, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (symbol_id) DO UPDATE SET
+			leverage = excluded.leverage, lot_size = excluded.lot_size,
+			contract_multiplier = excluded.contract_multiplier, price_unit = excluded.price_unit,
+			units = excluded.units, updated_at = excluded.updated_at`,
+		id, spec.Leverage, spec.LotSize, spec.ContractMultiplier, spec.PriceUnit, spec.Units, s.now().UnixMilli())
+	return err
+}
+
+// Specification returns a stored specification, or sql.ErrNoRows.
+func (s *Store) Specification(ctx context.Context, id string) (*exante.SymbolSpecification, error) {
+	var spec exante.SymbolSpecification
+	err := s.db.QueryRowContext(ctx, `
+		SELECT leverage, lot_size, contract_multiplier, price_unit, units
+		FROM specifications WHERE symbol_id = ?`, id).
+		Scan(&spec.Leverage, &spec.LotSize, &spec.ContractMultiplier, &spec.PriceUnit, &spec.Units)
+	if err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// SaveSchedule stores the intervals of a symbol schedule. Stored intervals
+// overlapping the new ones are replaced, older ones are kept as history.
+func (s *Store) SaveSchedule(ctx context.Context, id string, intervals []exante.SymbolScheduleInterval) error {
+	if len(intervals) == 0 {
+		return nil
+	}
+	first, last := intervals[0].Period.Start.Time, intervals[0].Period.End.Time
+	for _, in := range intervals {
+		if in.Period.Start.Before(first) {
+			first = in.Period.Start.Time
+		}
+		if in.Period.End.After(last) {
+			last = in.Period.End.Time
+		}
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schedules WHERE symbol_id = ? AND end > ? AND start < ?`,
+		id, first.UnixMilli(), last.UnixMilli()); err != nil {
+		return err
+	}
+	stmt, err := tx.PrepareContext(ctx, `INSERT OR REPLACE INTO schedules (symbol_id, start, end, name) VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, in := range intervals {
+		if _, err := stmt.ExecContext(ctx, id, in.Period.Start.UnixMilli(), in.Period.End.UnixMilli(), string(in.Name)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Schedule returns the stored intervals of a symbol overlapping [from, to),
+// ordered by start. Zero bounds are open.
+func (s *Store) Schedule(ctx context.Context, id string, from, to time.Time) ([]exante.SymbolScheduleInterval, error) {
+	lo, hi := bounds(from, to)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT name, start, end FROM schedules
+		WHERE symbol_id = ? AND end > ? AND start < ? ORDER BY start`, id, lo, hi)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var intervals []exante.SymbolScheduleInterval
+	for rows.Next() {
+		var (
+			in         exante.SymbolScheduleInterval
+			start, end int64
+		)
+		if err := rows.Scan(&in.Name, &start, &end); err != nil {
+			return nil, err
+		}
+		in.Period.Start = exante.Timestamp{Time: time.UnixMilli(start)}
+		in.Period.End = exante.Timestamp{Time: time.UnixMilli(end)}
+		intervals = append(intervals, in)
+	}
+	return intervals, rows.Err()
+}
+
+// SaveOHLC inserts or updates candles and advances the last stored candle
+// of the symbol and duration.
+func (s *Store) SaveOHLC(ctx context.Context, id string, duration exante.Duration, candles []exante.OHLC) error {
+	if len(candles) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO ohlc (symbol_id, duration, timestamp, open, high, low, close)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (symbol_id, duration, timestamp) DO UPDATE SET
+			open = excluded.open, high = excluded.high, low = excluded.low, close = excluded.close`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	var last int64
+	for _, c := range candles {
+		ts := c.Timestamp.UnixMilli()
+		if ts > last {
+			last = ts
+		}
+		if _, err := stmt.ExecContext(ctx, id, int(duration), ts, c.Open, c.High, c.Low, c.Close); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO ohlc_sync (symbol_id, duration, last, synced_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (symbol_id, duration) DO UPDATE SET
+			last = max(last, excluded.last), synced_at = excluded.synced_at`,
+		id, int(duration), last, s.now().UnixMilli()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// OHLC returns the stored candles in [from, to) in ascending order. Zero
+// bounds are open.
+func (s *Store) OHLC(ctx context.Context, id string, duration exante.Duration, from, to time.Time) ([]exante.OHLC, error) {
+	lo, hi := bounds(from, to)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT timestamp, open, high, low, close FROM ohlc
+		WHERE symbol_id = ? AND duration = ? AND timestamp >= ? AND timestamp < ?
+		ORDER BY timestamp`, id, int(duration), lo, hi)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var candles []exante.OHLC
+	for rows.Next() {
+		var (
+			c  exante.OHLC
+			ts int64
+		)
+		if err := rows.Scan(&ts, &c.Open, &c.High, &c.Low, &c.Close); err != nil {
+			return nil, err
+		}
+		c.Timestamp = exante.Timestamp{Time: time.UnixMilli(ts)}
+		candles = append(candles, c)
+	}
+	return candles, rows.Err()
+}
+
+// LastCandle returns the time of the newest stored candle of a symbol and
+// duration. It returns false when none was stored yet.
+func (s *Store) LastCandle(ctx context.Context, id string, duration exante.Duration) (time.Time, bool, error) {
+	var last int64
+	err := s.db.QueryRowContext(ctx, `SELECT last FROM ohlc_sync WHERE symbol_id = ? AND duration = ?`, id, int(duration)).Scan(&last)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return time.UnixMilli(last), true, nil
+}
+
+// tx runs fn with a statement prepared in a new transaction.
+func (s *Store) tx(ctx context.Context, query string, fn func(*sql.Tx, *sql.Stmt) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	if err := fn(tx, stmt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func bounds(from, to time.Time) (int64, int64) {
+	lo, hi := int64(-1<<63), int64(1<<63-1)
+	if !from.IsZero() {
+		lo = from.UnixMilli()
+	}
+	if !to.IsZero() {
+		hi = to.UnixMilli()
+	}
+	return lo, hi
+}
@@ -0,0 +1,176 @@
+package exantesqlite
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zerodivisi0n/exante-api-go"
+	"github.com/zerodivisi0n/exante-api-go/exantetest"
+)
+
+var day0 = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func openStore(t *testing.T) *Store {
+	s, err := Open(filepath.Join(t.TempDir(), "exante.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func candle(day int, close float64) exante.OHLC {
+	return exante.OHLC{Timestamp: exante.Timestamp{Time: day0.AddDate(0, 0, day)}, Open: close, High: close, Low: close, Close: close}
+}
+
+func TestSymbols(t *testing.T) {
+	ctx := context.Background()
+	s := openStore(t)
+
+	option := exante.Symbol{ID: "AAPL.CBOE.17J2025.C200", Ticker: "AAPL", Type: exante.SymbolTypeOption, Exchange: "CBOE",
+		Expiration: exante.Timestamp{Time: time.Date(2025, 10, 17, 20, 0, 0, 0, time.UTC)}}
+	option.OptionData.Right = exante.OptionCall
+	option.OptionData.StrikePrice = 200
+	stock := exante.Symbol{ID: "AAPL.NASDAQ", Ticker: "AAPL", Type: exante.SymbolTypeStock, Exchange: "NASDAQ", Currency: "USD", MPI: 0.01}
+	require.NoError(t, s.SaveSymbols(ctx, []exante.Symbol{stock, option}))
+
+	stock.Name = "Apple"
+	require.NoError(t, s.SaveSymbols(ctx, []exante.Symbol{stock}))
+
+	symbols, err := s.Symbols(ctx)
+	require.NoError(t, err)
+	require.Len(t, symbols, 2)
+	assert.Equal(t, option.ID, symbols[0].ID)
+	assert.True(t, option.Expiration.Equal(symbols[0].Expiration.Time))
+	assert.Equal(t, option.OptionData, symbols[0].OptionData)
+	assert.Equal(t, stock, symbols[1])
+
+	_, err = s.Symbol(ctx, "MSFT.NASDAQ")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestSpecificationAndSchedule(t *testing.T) {
+	ctx := context.Background()
+	s := openStore(t)
+
+	spec := &exante.SymbolSpecification{Leverage: 0.2, LotSize: 1, ContractMultiplier: 1, PriceUnit: 1, Units: "Shares"}
+	require.NoError(t, s.SaveSpecification(ctx, "AAPL.NASDAQ", spec))
+	read, err := s.Specification(ctx, "AAPL.NASDAQ")
+	require.NoError(t, err)
+	assert.Equal(t, spec, read)
+
+	session := func(day int, name exante.SessionName) exante.SymbolScheduleInterval {
+		var in exante.SymbolScheduleInterval
+		in.Name = name
+		in.Period.Start = exante.Timestamp{Time: day0.AddDate(0, 0, day).Add(14 * time.Hour)}
+		in.Period.End = exante.Timestamp{Time: day0.AddDate(0, 0, day).Add(21 * time.Hour)}
+		return in
+	}
+	require.NoError(t, s.SaveSchedule(ctx, "AAPL.NASDAQ", []exante.SymbolScheduleInterval{session(1, exante.SessionMain), session(2, exante.SessionMain)}))
+	// The exchange closes on day 2 after all, day 1 stays as history.
+	require.NoError(t, s.SaveSchedule(ctx, "AAPL.NASDAQ", []exante.SymbolScheduleInterval{session(2, exante.SessionClosed), session(3, exante.SessionMain)}))
+
+	intervals, err := s.Schedule(ctx, "AAPL.NASDAQ", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, intervals, 3)
+	assert.Equal(t, []exante.SessionName{exante.SessionMain, exante.SessionClosed, exante.SessionMain},
+		[]exante.SessionName{intervals[0].Name, intervals[1].Name, intervals[2].Name})
+
+	intervals, err = s.Schedule(ctx, "AAPL.NASDAQ", day0.AddDate(0, 0, 3), time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, intervals, 1)
+}
+
+func TestOHLC(t *testing.T) {
+	ctx := context.Background()
+	s := openStore(t)
+
+	_, ok, err := s.LastCandle(ctx, "AAPL.NASDAQ", exante.Duration1Day)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, s.SaveOHLC(ctx, "AAPL.NASDAQ", exante.Duration1Day, []exante.OHLC{candle(2, 2), candle(0, 0), candle(1, 1)}))
+	require.NoError(t, s.SaveOHLC(ctx, "AAPL.NASDAQ", exante.Duration1Day, []exante.OHLC{candle(1, 1.5)}))
+	require.NoError(t, s.SaveOHLC(ctx, "AAPL.NASDAQ", exante.Duration1Hour, []exante.OHLC{candle(5, 5)}))
+
+	last, ok, err := s.LastCandle(ctx, "AAPL.NASDAQ", exante.Duration1Day)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, last.Equal(day0.AddDate(0, 0, 2)), "not moved back by older candles")
+
+	candles, err := s.OHLC(ctx, "AAPL.NASDAQ", exante.Duration1Day, day0.AddDate(0, 0, 1), time.Time{})
+	require.NoError(t, err)
+	require.Len(t, candles, 2)
+	assert.Equal(t, 1.5, candles[0].Close, "upserted")
+	assert.Equal(t, 2.0, candles[1].Close)
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	srv := exantetest.NewServer()
+	defer srv.Close()
+	srv.AddSymbols(exante.Symbol{ID: "AAPL.NASDAQ", Type: exante.SymbolTypeStock, Exchange: "NASDAQ"})
+	srv.SetSpecification("AAPL.NASDAQ", exante.SymbolSpecification{LotSize: 1, Units: "Shares"})
+	for day := 0; day < 10; day++ {
+		srv.AddOHLC("AAPL.NASDAQ", exante.Duration1Day, candle(day, float64(day)))
+	}
+	client := srv.Client()
+	s := openStore(t)
+	require.NoError(t, s.SaveSymbols(ctx, []exante.Symbol{{ID: "DELISTED.NASDAQ"}}))
+
+	n, err := s.SyncSymbols(ctx, client)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	symbols, err := s.Symbols(ctx)
+	require.NoError(t, err)
+	require.Len(t, symbols, 1, "symbols missing from the list are deleted")
+	assert.Equal(t, "AAPL.NASDAQ", symbols[0].ID)
+	err = s.SyncSpecifications(ctx, client, []string{"AAPL.NASDAQ", "MSFT.NASDAQ"}, 2)
+	var batchErr exante.BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Contains(t, batchErr, "MSFT.NASDAQ")
+	_, err = s.Specification(ctx, "AAPL.NASDAQ")
+	assert.NoError(t, err, "stored despite the other failure")
+
+	opts := SyncOptions{Since: day0, To: day0.AddDate(0, 0, 6), PageSize: 4}
+	_, err = s.SyncOHLC(ctx, client, "AAPL.NASDAQ", exante.Duration1Day, SyncOptions{})
+	assert.Error(t, err, "Since is required on the first sync")
+	_, err = s.SyncOHLC(ctx, client, "AAPL.NASDAQ", exante.Duration1Day, SyncOptions{Since: day0, PageSize: -1})
+	assert.Error(t, err, "negative page size")
+	n, err = s.SyncOHLC(ctx, client, "AAPL.NASDAQ", exante.Duration1Day, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 6, n)
+
+	requests := len(srv.Requests())
+	opts.To = day0.AddDate(0, 0, 10)
+	n, err = s.SyncOHLC(ctx, client, "AAPL.NASDAQ", exante.Duration1Day, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 5, n, "the last stored candle and the new ones")
+	assert.Len(t, srv.Requests(), requests+2)
+
+	candles, err := s.OHLC(ctx, "AAPL.NASDAQ", exante.Duration1Day, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, candles, 10)
+}
+
+func TestReopen(t *testing.T) {
+	// Characters with a meaning in URIs are kept in the file name
+	path := filepath.Join(t.TempDir(), "exante?mode=ro#1%.db")
+	s, err := Open(path)
+	require.NoError(t, err)
+	require.NoError(t, s.SaveOHLC(context.Background(), "AAPL.NASDAQ", exante.Duration1Day, []exante.OHLC{candle(0, 1)}))
+	require.NoError(t, s.Close())
+
+	s, err = Open(path)
+	require.NoError(t, err)
+	defer s.Close()
+	_, ok, err := s.LastCandle(context.Background(), "AAPL.NASDAQ", exante.Duration1Day)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	_, err = os.Stat(path)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,104 @@
+package exantesqlite
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zerodivisi0n/exante-api-go"
+)
+
+// SyncSymbols replaces the stored symbols with the current list.
+func (s *Store) SyncSymbols(ctx context.Context, source exante.SymbolSource) (int, error) {
+	symbols, err := source.Symbols()
+	if err != nil {
+		return 0, err
+	}
+	return len(symbols), s.ReplaceSymbols(ctx, symbols)
+}
+
+// SyncSpecifications fetches and stores the specifications of many symbols
+// with at most concurrency parallel requests. Specifications fetched
+// successfully are stored even when others fail.
+func (s *Store) SyncSpecifications(ctx context.Context, client *exante.Client, ids []string, concurrency int) error {
+	specs, fetchErr := client.SymbolSpecifications(ctx, ids, concurrency)
+	for id, spec := range specs {
+		if err := s.SaveSpecification(ctx, id, spec); err != nil {
+			return err
+		}
+	}
+	return fetchErr
+}
+
+// SyncSchedules fetches and stores the schedules of many symbols, see
+// SyncSpecifications.
+func (s *Store) SyncSchedules(ctx context.Context, client *exante.Client, ids []string, concurrency int) error {
+	schedules, fetchErr := client.SymbolSchedules(ctx, ids, concurrency)
+	for id, intervals := range schedules {
+		if err := s.SaveSchedule(ctx, id, intervals); err != nil {
+			return err
+		}
+	}
+	return fetchErr
+}
+
+type SyncOptions struct {
+	// Start of the history when nothing is stored yet
+	Since time.Time
+	// End of the sync, now when zero
+	To time.Time
+	// Candles per request, exante.DefaultDownloadPageSize when zero
+	PageSize int
+}
+
+// SyncOHLC fetches the candles newer than the last stored one and returns
+// their number. The last stored candle is fetched again, as it may have
+// been incomplete when stored.
+func (s *Store) SyncOHLC(ctx context.Context, source exante.OHLCSource, id string, duration exante.Duration, opts SyncOptions) (int, error) {
+	if opts.PageSize == 0 {
+		opts.PageSize = exante.DefaultDownloadPageSize
+	}
+	if opts.PageSize < 0 || duration <= 0 {
+		return 0, errors.New("sync needs a positive duration and page size")
+	}
+	if opts.To.IsZero() {
+		opts.To = s.now()
+	}
+	from, ok, err := s.LastCandle(ctx, id, duration)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		if opts.Since.IsZero() {
+			return 0, errors.New("nothing stored yet, SyncOptions.Since is required")
+		}
+		from = opts.Since
+	}
+	return s.syncRange(ctx, source, id, duration, from, opts.To, opts.PageSize)
+}
+
+// syncRange fetches and stores the candles in [from, to) page by page.
+func (s *Store) syncRange(ctx context.Context, source exante.OHLCSource, id string, duration exante.Duration, from, to time.Time, pageSize int) (int, error) {
+	page := time.Duration(pageSize) * time.Duration(duration) * time.Second
+	total := 0
+	for from.Before(to) {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		end := from.Add(page)
+		if end.After(to) {
+			end = to
+		}
+		// The API range is inclusive, with second precision.
+		candles, err := source.OHLC(id, duration, from, end.Add(-time.Second), pageSize)
+		if err != nil {
+			return total, err
+		}
+		if err := s.SaveOHLC(ctx, id, duration, candles); err != nil {
+			return total, err
+		}
+		total += len(candles)
+		from = end
+	}
+	return total, nil
+}
@@ -0,0 +1,281 @@
+package exantetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Redacted replaces secrets in recorded cassettes.
+const Redacted = "REDACTED"
+
+// Mode selects whether a Recorder talks to the network.
+type Mode int
+
+const (
+	// ModeReplay serves responses from the cassette and never touches the
+	// network. Unmatched requests fail.
+	ModeReplay Mode = iota
+	// ModeRecord forwards requests to the real transport and appends them
+	// to the cassette.
+	ModeRecord
+)
+
+// Cassette is the file format of recorded traffic.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+}
+
+type RecordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+type RecorderOption func(*Recorder)
+
+// IgnoreParams excludes query parameters from request matching, e.g. "from"
+// and "to" of OHLC requests that are relative to the current time.
+func IgnoreParams(names ...string) RecorderOption {
+	return func(r *Recorder) {
+		for _, name := range names {
+			r.ignored[name] = true
+		}
+	}
+}
+
+// RedactValues replaces every occurrence of the given values in recorded
+// URLs, headers and bodies. The client and application IDs of recorded
+// requests are always redacted.
+func RedactValues(values ...string) RecorderOption {
+	return func(r *Recorder) {
+		for _, v := range values {
+			if v != "" {
+				r.secrets = append(r.secrets, v)
+			}
+		}
+	}
+}
+
+// WithRealTransport sets the transport used in record mode,
+// http.DefaultTransport by default.
+func WithRealTransport(rt http.RoundTripper) RecorderOption {
+	return func(r *Recorder) {
+		r.transport = rt
+	}
+}
+
+// Recorder is an http.RoundTripper recording API traffic to a cassette file
+// or replaying it from one. Pass it to exante.WithTransport. Requests match
+// a recorded interaction by method, path and query, never by headers, so
+// the changing Authorization token is ignored. Each interaction is replayed
+// once, in recorded order, so repeated requests may get different
+// responses.
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+	ignored   map[string]bool
+	secrets   []string
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// NewRecorder creates a recorder for the cassette at path. In replay mode
+// the cassette must exist. In record mode it is written by Stop.
+func NewRecorder(path string, mode Mode, opts ...RecorderOption) (*Recorder, error) {
+	r := &Recorder{
+		path:      path,
+		mode:      mode,
+		transport: http.DefaultTransport,
+		ignored:   make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("cassette %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+	return r, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.mode == ModeRecord {
+		return r.record(req)
+	}
+	return r.replay(req)
+}
+
+// Stop writes the cassette in record mode. It is a no-op in replay mode.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	res, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	reqHeader := req.Header.Clone()
+	credentials := tokenCredentials(reqHeader.Get("Authorization"))
+	if reqHeader.Get("Authorization") != "" {
+		reqHeader.Set("Authorization", Redacted)
+	}
+	resHeader := res.Header.Clone()
+	resHeader.Del("Set-Cookie")
+
+	r.mu.Lock()
+	for _, v := range credentials {
+		r.addSecret(v)
+	}
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    r.redact(req.URL.String()),
+			Header: r.redactHeader(reqHeader),
+		},
+		Response: RecordedResponse{
+			Status: res.StatusCode,
+			Header: r.redactHeader(resHeader),
+			Body:   r.redact(string(body)),
+		},
+	})
+	r.mu.Unlock()
+	return res, nil
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Recorded URLs hold the credentials of the client redacted
+	for _, v := range tokenCredentials(req.Header.Get("Authorization")) {
+		r.addSecret(v)
+	}
+	want := r.key(req.Method, r.redact(req.URL.String()))
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || r.key(in.Request.Method, in.Request.URL) != want {
+			continue
+		}
+		r.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+			StatusCode:    in.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Response.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("cassette %s: no interaction left for %s %s", r.path, req.Method, req.URL)
+}
+
+// key normalizes a request for matching: path and sorted query without
+// ignored parameters.
+func (r *Recorder) key(method, rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return method + " " + rawURL
+	}
+	query := u.Query()
+	for name := range r.ignored {
+		query.Del(name)
+	}
+	return method + " " + u.Path + "?" + query.Encode()
+}
+
+// tokenCredentials returns the client and application IDs of the client,
+// which are the issuer and subject of its bearer token.
+func tokenCredentials(auth string) []string {
+	token := strings.TrimPrefix(auth, "Bearer ")
+	if token == auth {
+		return nil
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(token, claims); err != nil {
+		return nil
+	}
+	var res []string
+	for _, name := range []string{"iss", "sub"} {
+		if v, ok := claims[name].(string); ok {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// addSecret adds a value to redact unless it is known already. Callers hold
+// mu.
+func (r *Recorder) addSecret(v string) {
+	if v == "" {
+		return
+	}
+	for _, secret := range r.secrets {
+		if secret == v {
+			return
+		}
+	}
+	r.secrets = append(r.secrets, v)
+}
+
+func (r *Recorder) redact(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, Redacted)
+	}
+	return s
+}
+
+func (r *Recorder) redactHeader(h http.Header) http.Header {
+	for _, values := range h {
+		for i, v := range values {
+			values[i] = r.redact(v)
+		}
+	}
+	return h
+}
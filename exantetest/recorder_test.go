@@ -0,0 +1,64 @@
+package exantetest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zerodivisi0n/exante-api-go"
+)
+
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := NewServer()
+	seed(s)
+	s.AddOHLC("AAPL.NASDAQ", exante.Duration1Day, exante.OHLC{Timestamp: exante.Timestamp{Time: start}, Close: 185.5})
+	rec, err := NewRecorder(path, ModeRecord, IgnoreParams("from", "to"))
+	require.NoError(t, err)
+	client := s.Client(exante.WithTransport(rec))
+
+	symbols, err := client.Symbols()
+	require.NoError(t, err)
+	candles, err := client.OHLC("AAPL.NASDAQ", exante.Duration1Day, start, start.AddDate(0, 0, 1), 10)
+	require.NoError(t, err)
+	_, err = client.Symbol("MSFT.NASDAQ")
+	require.Error(t, err)
+	_, err = client.Symbol(ApplicationID)
+	require.Error(t, err)
+	require.NoError(t, rec.Stop())
+	s.Close()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Bearer")
+	assert.NotContains(t, string(data), ClientID, "redacted without RedactValues")
+	assert.NotContains(t, string(data), ApplicationID)
+	assert.NotContains(t, string(data), SharedKey)
+
+	rec, err = NewRecorder(path, ModeReplay, IgnoreParams("from", "to"))
+	require.NoError(t, err)
+	client = exante.NewClient("other", "credentials", "entirely", exante.WithBaseURL(s.URL), exante.WithTransport(rec))
+
+	replayed, err := client.Symbols()
+	require.NoError(t, err)
+	assert.Equal(t, symbols[0].ID, replayed[0].ID)
+	assert.Len(t, replayed, len(symbols))
+
+	later := start.AddDate(0, 1, 0)
+	replayedCandles, err := client.OHLC("AAPL.NASDAQ", exante.Duration1Day, later, later.AddDate(0, 0, 1), 10)
+	require.NoError(t, err)
+	assert.Equal(t, candles[0].Close, replayedCandles[0].Close)
+
+	_, err = client.Symbol("MSFT.NASDAQ")
+	assert.Error(t, err, "recorded errors are replayed")
+
+	_, err = client.OHLC("AAPL.NASDAQ", exante.Duration1Day, later, later, 5)
+	assert.Error(t, err, "size is still matched")
+	_, err = client.Symbols()
+	assert.Error(t, err, "interactions are used once")
+}
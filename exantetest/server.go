@@ -0,0 +1,545 @@
+// Package exantetest provides an in-memory fake of the Exante market data
+// API for tests of code using exante.Client.
+//
+//	srv := exantetest.NewServer()
+//	defer srv.Close()
+//	srv.AddSymbols(exante.Symbol{ID: "AAPL.NASDAQ", Type: exante.SymbolTypeStock})
+//	client := srv.Client()
+package exantetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/zerodivisi0n/exante-api-go"
+)
+
+// Default credentials accepted by a new server.
+const (
+	ClientID      = "test-client"
+	ApplicationID = "test-application"
+	SharedKey     = "test-shared-key"
+)
+
+// Quote is the last quote of a symbol served at /feed/{id}/last.
+type Quote struct {
+	Timestamp time.Time
+	Bid       float64
+	BidSize   float64
+	Ask       float64
+	AskSize   float64
+}
+
+// Fault alters the responses of matching requests.
+type Fault struct {
+	// Path prefix of affected requests, e.g. "/ohlc/". Empty matches all.
+	Path string
+	// Number of requests affected, unlimited when zero
+	Times int
+	// Delay before responding
+	Latency time.Duration
+	// Reply with this status code instead of serving the request,
+	// e.g. http.StatusTooManyRequests or http.StatusBadGateway
+	Status int
+	// Reply 200 with a body that is not valid JSON
+	Malformed bool
+}
+
+// Server is a fake Exante API backed by an httptest.Server. Seed data may
+// be changed at any time, also while requests are served.
+type Server struct {
+	URL string
+
+	ClientID      string
+	ApplicationID string
+	SharedKey     string
+	// Now is the server clock, used for JWT validation and nearest
+	// contract lookups
+	Now func() time.Time
+
+	srv *httptest.Server
+
+	mu        sync.Mutex
+	symbols   []exante.Symbol
+	exchanges []exante.Exchange
+	groups    []exante.Group
+	types     []exante.SymbolType
+	specs     map[string]exante.SymbolSpecification
+	schedules map[string][]exante.SymbolScheduleInterval
+	candles   map[string]map[exante.Duration][]exante.OHLC
+	quotes    map[string]Quote
+	faults    []*Fault
+	requests  []string
+}
+
+// NewServer starts a server accepting the default credentials.
+func NewServer() *Server {
+	s := &Server{
+		ClientID:      ClientID,
+		ApplicationID: ApplicationID,
+		SharedKey:     SharedKey,
+		Now:           time.Now,
+		specs:         make(map[string]exante.SymbolSpecification),
+		schedules:     make(map[string][]exante.SymbolScheduleInterval),
+		candles:       make(map[string]map[exante.Duration][]exante.OHLC),
+		quotes:        make(map[string]Quote),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	return s
+}
+
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns a client configured with the server URL and credentials.
+func (s *Server) Client(opts ...exante.Option) *exante.Client {
+	opts = append([]exante.Option{exante.WithBaseURL(s.URL)}, opts...)
+	return exante.NewClient(s.ClientID, s.ApplicationID, s.SharedKey, opts...)
+}
+
+// Token returns a valid bearer token for the scope, for raw HTTP requests.
+func (s *Server) Token(scope string) string {
+	now := s.Now()
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss": s.ClientID,
+		"sub": s.ApplicationID,
+		"aud": []string{scope},
+		"iat": now.Unix(),
+		"exp": now.Add(time.Minute).Unix(),
+	}).SignedString([]byte(s.SharedKey))
+	return token
+}
+
+func (s *Server) AddSymbols(symbols ...exante.Symbol) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.symbols = append(s.symbols, symbols...)
+}
+
+func (s *Server) AddExchanges(exchanges ...exante.Exchange) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exchanges = append(s.exchanges, exchanges...)
+}
+
+func (s *Server) AddGroups(groups ...exante.Group) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.groups = append(s.groups, groups...)
+}
+
+// SetTypes overrides the types list, which defaults to the distinct types
+// of the seeded symbols.
+func (s *Server) SetTypes(types ...exante.SymbolType) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.types = types
+}
+
+func (s *Server) SetSpecification(id string, spec exante.SymbolSpecification) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.specs[id] = spec
+}
+
+func (s *Server) SetSchedule(id string, intervals []exante.SymbolScheduleInterval) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedules[id] = intervals
+}
+
+// AddOHLC adds candles to the history of a symbol.
+func (s *Server) AddOHLC(id string, duration exante.Duration, candles ...exante.OHLC) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.candles[id] == nil {
+		s.candles[id] = make(map[exante.Duration][]exante.OHLC)
+	}
+	s.candles[id][duration] = append(s.candles[id][duration], candles...)
+}
+
+func (s *Server) SetQuote(id string, quote Quote) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quotes[id] = quote
+}
+
+// InjectFault registers a fault. Faults are checked in registration order
+// and the first matching one applies.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns the paths of all requests received so far.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	s.mu.Lock()
+	s.requests = append(s.requests, path)
+	fault := s.matchFault(path)
+	s.mu.Unlock()
+
+	if fault != nil {
+		if fault.Latency > 0 {
+			// Give up when the client does, so that tests of timeouts do
+			// not leave handlers behind.
+			timer := time.NewTimer(fault.Latency)
+			select {
+			case <-timer.C:
+			case <-r.Context().Done():
+				timer.Stop()
+				return
+			}
+		}
+		if fault.Status != 0 {
+			if fault.Status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "1")
+			}
+			writeError(w, fault.Status, http.StatusText(fault.Status))
+			return
+		}
+	}
+	w.Header().Set("Date", s.Now().UTC().Format(http.TimeFormat))
+
+	scope := "symbols"
+	if strings.HasPrefix(path, "/ohlc/") {
+		scope = "ohlc"
+	} else if strings.HasPrefix(path, "/feed/") {
+		scope = "feed"
+	}
+	if err := s.authorize(r, scope); err != nil {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	res, status := s.route(path, r)
+	if status != http.StatusOK {
+		writeError(w, status, http.StatusText(status))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if fault != nil && fault.Malformed {
+		w.Write([]byte(`{"malformed": [`))
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+func (s *Server) matchFault(path string) *Fault {
+	for i, f := range s.faults {
+		if !strings.HasPrefix(path, f.Path) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Server) authorize(r *http.Request, scope string) error {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return fmt.Errorf("missing bearer token")
+	}
+	parser := jwt.Parser{
+		ValidMethods:         []string{jwt.SigningMethodHS256.Alg()},
+		SkipClaimsValidation: true,
+	}
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(strings.TrimPrefix(auth, "Bearer "), claims, func(*jwt.Token) (interface{}, error) {
+		return []byte(s.SharedKey), nil
+	})
+	if err != nil {
+		return err
+	}
+	now := s.Now().Unix()
+	if !claims.VerifyExpiresAt(now, true) {
+		return fmt.Errorf("token is expired")
+	}
+	if !claims.VerifyIssuedAt(now, true) {
+		return fmt.Errorf("token used before issued")
+	}
+	if !claims.VerifyIssuer(s.ClientID, true) || claims["sub"] != s.ApplicationID {
+		return fmt.Errorf("unknown client or application")
+	}
+	aud, _ := claims["aud"].([]interface{})
+	for _, a := range aud {
+		if a == scope {
+			return nil
+		}
+	}
+	return fmt.Errorf("token is not valid for scope %s", scope)
+}
+
+func (s *Server) route(path string, r *http.Request) (interface{}, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case path == "/symbols":
+		return wireSymbols(s.symbols), http.StatusOK
+	case strings.HasPrefix(path, "/symbols/") && strings.HasSuffix(path, "/specification"):
+		spec, ok := s.specs[strings.TrimSuffix(strings.TrimPrefix(path, "/symbols/"), "/specification")]
+		if !ok {
+			return nil, http.StatusNotFound
+		}
+		return wireSpecification(spec), http.StatusOK
+	case strings.HasPrefix(path, "/symbols/") && strings.HasSuffix(path, "/schedule"):
+		intervals, ok := s.schedules[strings.TrimSuffix(strings.TrimPrefix(path, "/symbols/"), "/schedule")]
+		if !ok {
+			return nil, http.StatusNotFound
+		}
+		return wireSchedule(intervals), http.StatusOK
+	case strings.HasPrefix(path, "/symbols/"):
+		return s.symbol(strings.TrimPrefix(path, "/symbols/"))
+	case path == "/exchanges":
+		res := make([]map[string]interface{}, len(s.exchanges))
+		for i, e := range s.exchanges {
+			res[i] = map[string]interface{}{"id": e.ID, "name": e.Name, "country": e.Country}
+		}
+		return res, http.StatusOK
+	case strings.HasPrefix(path, "/exchanges/"):
+		id := strings.TrimPrefix(path, "/exchanges/")
+		return wireSymbols(s.filter(func(sym *exante.Symbol) bool { return sym.Exchange == id })), http.StatusOK
+	case path == "/types":
+		types := s.types
+		if types == nil {
+			types = s.symbolTypes()
+		}
+		res := make([]map[string]interface{}, len(types))
+		for i, t := range types {
+			res[i] = map[string]interface{}{"id": t}
+		}
+		return res, http.StatusOK
+	case strings.HasPrefix(path, "/types/"):
+		id := exante.SymbolType(strings.TrimPrefix(path, "/types/"))
+		return wireSymbols(s.filter(func(sym *exante.Symbol) bool { return sym.Type == id })), http.StatusOK
+	case path == "/groups":
+		res := make([]map[string]interface{}, len(s.groups))
+		for i, g := range s.groups {
+			res[i] = map[string]interface{}{"group": g.Group, "name": g.Name, "types": g.Types, "exchange": g.Exchange}
+		}
+		return res, http.StatusOK
+	case strings.HasPrefix(path, "/groups/") && strings.HasSuffix(path, "/nearest"):
+		return s.nearest(strings.TrimSuffix(strings.TrimPrefix(path, "/groups/"), "/nearest"))
+	case strings.HasPrefix(path, "/groups/"):
+		id := strings.TrimPrefix(path, "/groups/")
+		return wireSymbols(s.filter(func(sym *exante.Symbol) bool { return sym.Group == id })), http.StatusOK
+	case strings.HasPrefix(path, "/ohlc/"):
+		return s.ohlc(strings.TrimPrefix(path, "/ohlc/"), r)
+	case strings.HasPrefix(path, "/feed/") && strings.HasSuffix(path, "/last"):
+		return s.quote(strings.TrimSuffix(strings.TrimPrefix(path, "/feed/"), "/last"))
+	}
+	return nil, http.StatusNotFound
+}
+
+func (s *Server) symbol(id string) (interface{}, int) {
+	for i := range s.symbols {
+		if s.symbols[i].ID == id {
+			return wireSymbol(&s.symbols[i]), http.StatusOK
+		}
+	}
+	return nil, http.StatusNotFound
+}
+
+func (s *Server) filter(fn func(*exante.Symbol) bool) []exante.Symbol {
+	var res []exante.Symbol
+	for i := range s.symbols {
+		if fn(&s.symbols[i]) {
+			res = append(res, s.symbols[i])
+		}
+	}
+	return res
+}
+
+func (s *Server) symbolTypes() []exante.SymbolType {
+	seen := make(map[exante.SymbolType]bool)
+	var res []exante.SymbolType
+	for _, sym := range s.symbols {
+		if !seen[sym.Type] {
+			seen[sym.Type] = true
+			res = append(res, sym.Type)
+		}
+	}
+	return res
+}
+
+func (s *Server) nearest(group string) (interface{}, int) {
+	now := s.Now()
+	var best *exante.Symbol
+	for i := range s.symbols {
+		sym := &s.symbols[i]
+		if sym.Group != group || sym.Expiration.Before(now) {
+			continue
+		}
+		if best == nil || sym.Expiration.Before(best.Expiration.Time) {
+			best = sym
+		}
+	}
+	if best == nil {
+		return nil, http.StatusNotFound
+	}
+	return wireSymbol(best), http.StatusOK
+}
+
+// ohlc serves candles between from and to, newest first, as the API does.
+func (s *Server) ohlc(rest string, r *http.Request) (interface{}, int) {
+	i := strings.LastIndexByte(rest, '/')
+	if i < 0 {
+		return nil, http.StatusNotFound
+	}
+	duration, err := strconv.Atoi(rest[i+1:])
+	if err != nil {
+		return nil, http.StatusBadRequest
+	}
+	history, ok := s.candles[rest[:i]][exante.Duration(duration)]
+	if !ok {
+		return nil, http.StatusNotFound
+	}
+	query := r.URL.Query()
+	from, _ := strconv.ParseInt(query.Get("from"), 10, 64)
+	// Zero bounds and size are sent by the client for unset values
+	to, _ := strconv.ParseInt(query.Get("to"), 10, 64)
+	if to <= 0 {
+		to = 1<<63 - 1
+	}
+	size, _ := strconv.Atoi(query.Get("size"))
+	if size <= 0 {
+		size = len(history)
+	}
+	var candles []exante.OHLC
+	for _, c := range history {
+		ts := c.Timestamp.Unix() * 1000
+		if ts >= from && ts <= to {
+			candles = append(candles, c)
+		}
+	}
+	sort.Slice(candles, func(i, j int) bool {
+		return candles[i].Timestamp.After(candles[j].Timestamp.Time)
+	})
+	if len(candles) > size {
+		candles = candles[:size]
+	}
+	res := make([]map[string]interface{}, len(candles))
+	for i, c := range candles {
+		res[i] = map[string]interface{}{
+			"timestamp": c.Timestamp,
+			"open":      c.Open,
+			"high":      c.High,
+			"low":       c.Low,
+			"close":     c.Close,
+		}
+	}
+	return res, http.StatusOK
+}
+
+func (s *Server) quote(id string) (interface{}, int) {
+	q, ok := s.quotes[id]
+	if !ok {
+		return nil, http.StatusNotFound
+	}
+	return map[string]interface{}{
+		"symbolId":  id,
+		"timestamp": exante.Timestamp{Time: q.Timestamp},
+		"bid":       []map[string]float64{{"value": q.Bid, "size": q.BidSize}},
+		"ask":       []map[string]float64{{"value": q.Ask, "size": q.AskSize}},
+	}, http.StatusOK
+}
+
+func wireSymbols(symbols []exante.Symbol) []map[string]interface{} {
+	res := make([]map[string]interface{}, len(symbols))
+	for i := range symbols {
+		res[i] = wireSymbol(&symbols[i])
+	}
+	return res
+}
+
+// wireSymbol renders a symbol the way the API does, omitting empty fields.
+func wireSymbol(s *exante.Symbol) map[string]interface{} {
+	res := map[string]interface{}{
+		"id":          s.ID,
+		"ticker":      s.Ticker,
+		"type":        s.Type,
+		"description": s.Description,
+		"currency":    s.Currency,
+		"mpi":         s.MPI,
+		"i18n":        map[string]string{},
+	}
+	for key, value := range map[string]string{
+		"name":     s.Name,
+		"exchange": s.Exchange,
+		"country":  s.Country,
+		"group":    s.Group,
+	} {
+		if value != "" {
+			res[key] = value
+		}
+	}
+	if !s.Expiration.IsZero() {
+		res["expiration"] = s.Expiration
+	}
+	if s.OptionData.Right != "" {
+		res["optionData"] = map[string]interface{}{
+			"right":       s.OptionData.Right,
+			"strikePrice": s.OptionData.StrikePrice,
+		}
+	}
+	return res
+}
+
+func wireSpecification(spec exante.SymbolSpecification) map[string]interface{} {
+	return map[string]interface{}{
+		"leverage":           spec.Leverage,
+		"lotSize":            spec.LotSize,
+		"contractMultiplier": spec.ContractMultiplier,
+		"priceUnit":          spec.PriceUnit,
+		"units":              spec.Units,
+	}
+}
+
+func wireSchedule(intervals []exante.SymbolScheduleInterval) map[string]interface{} {
+	res := make([]map[string]interface{}, len(intervals))
+	for i, in := range intervals {
+		res[i] = map[string]interface{}{
+			"name": in.Name,
+			"period": map[string]interface{}{
+				"start": in.Period.Start,
+				"end":   in.Period.End,
+			},
+		}
+	}
+	return map[string]interface{}{"intervals": res}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
@@ -0,0 +1,207 @@
+package exantetest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zerodivisi0n/exante-api-go"
+)
+
+func seed(s *Server) {
+	s.AddSymbols(
+		exante.Symbol{ID: "AAPL.NASDAQ", Ticker: "AAPL", Type: exante.SymbolTypeStock, Exchange: "NASDAQ", Currency: "USD", MPI: 0.01},
+		exante.Symbol{ID: "ES.CME.H2030", Ticker: "ES", Type: exante.SymbolTypeFuture, Exchange: "CME", Group: "ES",
+			Expiration: exante.Timestamp{Time: time.Date(2030, 3, 15, 0, 0, 0, 0, time.UTC)}},
+		exante.Symbol{ID: "ES.CME.M2030", Ticker: "ES", Type: exante.SymbolTypeFuture, Exchange: "CME", Group: "ES",
+			Expiration: exante.Timestamp{Time: time.Date(2030, 6, 21, 0, 0, 0, 0, time.UTC)}},
+	)
+	s.AddExchanges(exante.Exchange{ID: "NASDAQ", Name: "NASDAQ", Country: "US"})
+	s.AddGroups(exante.Group{Group: "ES", Name: "E-mini S&P 500", Types: []exante.SymbolType{exante.SymbolTypeFuture}, Exchange: "CME"})
+}
+
+func TestServerSymbols(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	seed(s)
+	client := s.Client()
+
+	symbols, err := client.Symbols()
+	require.NoError(t, err)
+	require.Len(t, symbols, 3)
+	assert.Equal(t, "AAPL.NASDAQ", symbols[0].ID)
+	assert.Equal(t, 0.01, symbols[0].MPI)
+	assert.Equal(t, 2030, symbols[1].Expiration.Year())
+
+	symbol, err := client.Symbol("AAPL.NASDAQ")
+	require.NoError(t, err)
+	assert.Equal(t, exante.SymbolTypeStock, symbol.Type)
+
+	_, err = client.Symbol("MSFT.NASDAQ")
+	assert.Error(t, err)
+
+	exchanges, err := client.Exchanges()
+	require.NoError(t, err)
+	assert.Equal(t, []exante.Exchange{{ID: "NASDAQ", Name: "NASDAQ", Country: "US"}}, exchanges)
+
+	cme, err := client.ExchangeSymbols("CME")
+	require.NoError(t, err)
+	assert.Len(t, cme, 2)
+
+	types, err := client.Types()
+	require.NoError(t, err)
+	assert.Equal(t, []exante.SymbolType{exante.SymbolTypeStock, exante.SymbolTypeFuture}, types)
+
+	groups, err := client.Groups()
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, []exante.SymbolType{exante.SymbolTypeFuture}, groups[0].Types)
+
+	s.Now = func() time.Time { return time.Date(2030, 4, 1, 0, 0, 0, 0, time.UTC) }
+	nearest, err := client.GroupNearestSymbol("ES")
+	require.NoError(t, err)
+	assert.Equal(t, "ES.CME.M2030", nearest.ID)
+}
+
+func TestServerSpecificationAndSchedule(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.SetSpecification("AAPL.NASDAQ", exante.SymbolSpecification{Leverage: 0.2, LotSize: 1, ContractMultiplier: 1, PriceUnit: 1, Units: "Shares"})
+	var in exante.SymbolScheduleInterval
+	in.Name = exante.SessionMain
+	in.Period.Start = exante.Timestamp{Time: time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)}
+	in.Period.End = exante.Timestamp{Time: time.Date(2024, 1, 2, 21, 0, 0, 0, time.UTC)}
+	s.SetSchedule("AAPL.NASDAQ", []exante.SymbolScheduleInterval{in})
+	client := s.Client()
+
+	spec, err := client.SymbolSpecification("AAPL.NASDAQ")
+	require.NoError(t, err)
+	assert.Equal(t, "Shares", spec.Units)
+	assert.Equal(t, 0.2, spec.Leverage)
+
+	intervals, err := client.SymbolSchedule("AAPL.NASDAQ")
+	require.NoError(t, err)
+	require.Len(t, intervals, 1)
+	assert.Equal(t, exante.SessionMain, intervals[0].Name)
+	assert.True(t, intervals[0].Period.End.Equal(in.Period.End.Time))
+}
+
+func TestServerOHLC(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		s.AddOHLC("AAPL.NASDAQ", exante.Duration1Day, exante.OHLC{
+			Timestamp: exante.Timestamp{Time: start.AddDate(0, 0, i)},
+			Open:      float64(i), High: float64(i), Low: float64(i), Close: float64(i),
+		})
+	}
+	client := s.Client()
+
+	candles, err := client.OHLC("AAPL.NASDAQ", exante.Duration1Day, start.AddDate(0, 0, 1), start.AddDate(0, 0, 3), 0)
+	require.NoError(t, err)
+	require.Len(t, candles, 3)
+	assert.Equal(t, 3.0, candles[0].Close, "newest first")
+	assert.Equal(t, 1.0, candles[2].Close)
+
+	candles, err = client.OHLC("AAPL.NASDAQ", exante.Duration1Day, time.Time{}, time.Time{}, 2)
+	require.NoError(t, err)
+	assert.Len(t, candles, 2)
+}
+
+func TestServerQuote(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.SetQuote("AAPL.NASDAQ", Quote{Timestamp: time.Unix(1700000000, 0), Bid: 189.5, BidSize: 100, Ask: 189.6, AskSize: 200})
+
+	req, _ := http.NewRequest("GET", s.URL+"/feed/AAPL.NASDAQ/last", nil)
+	req.Header.Set("Authorization", "Bearer "+s.Token("feed"))
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var quote struct {
+		SymbolID string
+		Bid      []struct{ Value, Size float64 }
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&quote))
+	assert.Equal(t, "AAPL.NASDAQ", quote.SymbolID)
+	assert.Equal(t, 189.5, quote.Bid[0].Value)
+
+	req.Header.Set("Authorization", "Bearer "+s.Token("symbols"))
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "wrong scope")
+}
+
+func TestServerAuth(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	client := exante.NewClient(ClientID, ApplicationID, "wrong-key", exante.WithBaseURL(s.URL))
+	_, err := client.Symbols()
+	assert.Error(t, err)
+
+	client = exante.NewClient("other", ApplicationID, SharedKey, exante.WithBaseURL(s.URL))
+	_, err = client.Symbols()
+	assert.Error(t, err)
+
+	resp, err := http.Get(s.URL + "/symbols")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestServerFaults(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	seed(s)
+	client := s.Client()
+
+	s.InjectFault(Fault{Path: "/symbols", Times: 1, Status: http.StatusTooManyRequests})
+	_, err := client.Symbols()
+	assert.Error(t, err)
+	_, err = client.Symbols()
+	assert.NoError(t, err, "fault expired")
+
+	s.InjectFault(Fault{Path: "/exchanges", Status: http.StatusBadGateway})
+	_, err = client.Exchanges()
+	assert.Error(t, err)
+	_, err = client.Symbols()
+	assert.NoError(t, err, "other paths unaffected")
+	s.ClearFaults()
+	_, err = client.Exchanges()
+	assert.NoError(t, err)
+
+	s.InjectFault(Fault{Malformed: true, Times: 1})
+	_, err = client.Groups()
+	assert.Error(t, err)
+
+	s.InjectFault(Fault{Latency: 50 * time.Millisecond, Times: 1})
+	started := time.Now()
+	_, err = client.Types()
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(started), 50*time.Millisecond)
+
+	assert.Equal(t, []string{"/symbols", "/symbols", "/exchanges", "/symbols", "/exchanges", "/groups", "/types"}, s.Requests())
+}
+
+func TestServerFaultLatencyCanceled(t *testing.T) {
+	s := NewServer()
+	seed(s)
+	s.InjectFault(Fault{Latency: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	_, err := s.Client().WithContext(ctx).Types()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	// Close waits for running handlers
+	s.Close()
+	assert.Less(t, time.Since(started), 10*time.Second)
+}
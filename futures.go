@@ -0,0 +1,234 @@
+package exante
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RollAdjustment selects how prices before a roll are adjusted to remove
+// the gap between the expiring and the next contract.
+type RollAdjustment int
+
+const (
+	NoAdjustment RollAdjustment = iota
+	// Add the price difference between the contracts at each roll
+	DifferenceAdjustment
+	// Multiply by the price ratio between the contracts at each roll
+	RatioAdjustment
+)
+
+type RollOptions struct {
+	// Switch to the next contract this many days before expiration
+	DaysBeforeExpiry int
+	Adjustment       RollAdjustment
+	// Candles per request, DefaultDownloadPageSize when zero
+	PageSize int
+}
+
+// FuturesContracts returns the futures of a group ordered by expiration.
+func (c *Client) FuturesContracts(groupID string) ([]Symbol, error) {
+	symbols, err := c.GroupSymbols(groupID)
+	if err != nil {
+		return nil, err
+	}
+	return futuresContracts(symbols), nil
+}
+
+// ContinuousOHLC builds a continuous series for a futures group over the
+// given period by stitching the history of consecutive contracts. Candles
+// are returned in ascending time order.
+func (c *Client) ContinuousOHLC(groupID string, duration Duration, from, to time.Time, opts RollOptions) ([]OHLC, error) {
+	if opts.PageSize == 0 {
+		opts.PageSize = DefaultDownloadPageSize
+	}
+	if opts.PageSize < 0 || duration <= 0 {
+		return nil, errors.New("continuous OHLC needs a positive duration and page size")
+	}
+	contracts, err := c.FuturesContracts(groupID)
+	if err != nil {
+		return nil, err
+	}
+	step := time.Duration(duration) * time.Second
+	history := make(map[string][]OHLC)
+	for i, s := range contracts {
+		start, end := rollWindow(contracts, i, opts)
+		if start.IsZero() || start.Before(from) {
+			start = from
+		}
+		if end.IsZero() || end.After(to) {
+			end = to
+		}
+		if !start.Before(end) {
+			continue
+		}
+		// Candles before the window give the price of the new contract at
+		// the last candle of the previous one, which may be older than one
+		// step when the roll follows a non-trading period.
+		start = start.Add(-step)
+		if i > 0 {
+			if prev := history[contracts[i-1].ID]; len(prev) > 0 {
+				if last := sortedCandles(prev)[len(prev)-1].Timestamp; last.Before(start) {
+					start = last.Time
+				}
+			}
+		}
+		candles, err := c.pagedOHLC(s.ID, duration, start, end, opts.PageSize)
+		if err != nil {
+			return nil, err
+		}
+		history[s.ID] = candles
+	}
+	series, err := StitchContinuous(contracts, history, opts)
+	if err != nil {
+		return nil, err
+	}
+	// Drop the candles fetched outside of the period for adjustments
+	res := series[:0]
+	for _, candle := range series {
+		if !candle.Timestamp.Before(from) && !candle.Timestamp.After(to) {
+			res = append(res, candle)
+		}
+	}
+	return res, nil
+}
+
+// pagedOHLC fetches the candles between from and to inclusive with requests
+// of at most pageSize candles each.
+func (c *Client) pagedOHLC(id string, duration Duration, from, to time.Time, pageSize int) ([]OHLC, error) {
+	page := time.Duration(pageSize) * time.Duration(duration) * time.Second
+	var res []OHLC
+	for next := from; !next.After(to); next = next.Add(page) {
+		// The API range is inclusive, with second precision.
+		end := next.Add(page - time.Second)
+		if end.After(to) {
+			end = to
+		}
+		candles, err := c.OHLC(id, duration, next, end, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, candle := range candles {
+			if !candle.Timestamp.Before(next) && !candle.Timestamp.After(end) {
+				res = append(res, candle)
+			}
+		}
+	}
+	return res, nil
+}
+
+// StitchContinuous joins the candles of consecutive futures contracts into
+// a continuous series. contracts must be ordered by expiration and history
+// holds the candles of each contract by symbol ID. Adjusting a roll needs a
+// candle of the new contract at or before the last candle of the expiring
+// one, it is an error if there is none.
+func StitchContinuous(contracts []Symbol, history map[string][]OHLC, opts RollOptions) ([]OHLC, error) {
+	segments := make([][]OHLC, len(contracts))
+	for i, s := range contracts {
+		start, end := rollWindow(contracts, i, opts)
+		for _, candle := range sortedCandles(history[s.ID]) {
+			if !start.IsZero() && candle.Timestamp.Before(start) {
+				continue
+			}
+			if !end.IsZero() && !candle.Timestamp.Before(end) {
+				continue
+			}
+			segments[i] = append(segments[i], candle)
+		}
+	}
+
+	if opts.Adjustment != NoAdjustment {
+		// Adjustments accumulate from the most recent roll backwards, so
+		// the latest contract keeps its actual prices.
+		add, mul := 0.0, 1.0
+		for i := len(segments) - 1; i >= 0; i-- {
+			for j := range segments[i] {
+				adjustCandle(&segments[i][j], add, mul)
+			}
+			if i == 0 || len(segments[i-1]) == 0 {
+				continue
+			}
+			last := segments[i-1][len(segments[i-1])-1]
+			next, ok := candleAt(sortedCandles(history[contracts[i].ID]), last.Timestamp.Time)
+			if !ok {
+				return nil, fmt.Errorf("no price of %s at the roll from %s on %s",
+					contracts[i].ID, contracts[i-1].ID, last.Timestamp.UTC().Format(time.RFC3339))
+			}
+			// segments[i-1] is not adjusted yet, so last holds raw prices
+			switch opts.Adjustment {
+			case DifferenceAdjustment:
+				add += next.Close - last.Close
+			case RatioAdjustment:
+				if last.Close != 0 {
+					mul *= next.Close / last.Close
+				}
+			}
+		}
+	}
+
+	var res []OHLC
+	for _, segment := range segments {
+		res = append(res, segment...)
+	}
+	return res, nil
+}
+
+func futuresContracts(symbols []Symbol) []Symbol {
+	var res []Symbol
+	for _, s := range symbols {
+		if !s.OptionData.Right.IsKnown() && !s.Expiration.IsZero() {
+			res = append(res, s)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Expiration.Before(res[j].Expiration.Time)
+	})
+	return res
+}
+
+// rollWindow returns the period in which contract i is the active one. A
+// zero start or end means the window is open on that side.
+func rollWindow(contracts []Symbol, i int, opts RollOptions) (start, end time.Time) {
+	rollDate := func(s Symbol) time.Time {
+		return s.Expiration.AddDate(0, 0, -opts.DaysBeforeExpiry)
+	}
+	if i > 0 {
+		start = rollDate(contracts[i-1])
+	}
+	if i < len(contracts)-1 {
+		end = rollDate(contracts[i])
+	}
+	return start, end
+}
+
+func sortedCandles(candles []OHLC) []OHLC {
+	if sort.SliceIsSorted(candles, func(i, j int) bool {
+		return candles[i].Timestamp.Before(candles[j].Timestamp.Time)
+	}) {
+		return candles
+	}
+	res := append([]OHLC(nil), candles...)
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Timestamp.Before(res[j].Timestamp.Time)
+	})
+	return res
+}
+
+// candleAt returns the latest candle not after t.
+func candleAt(candles []OHLC, t time.Time) (OHLC, bool) {
+	i := sort.Search(len(candles), func(i int) bool {
+		return candles[i].Timestamp.After(t)
+	})
+	if i == 0 {
+		return OHLC{}, false
+	}
+	return candles[i-1], true
+}
+
+func adjustCandle(c *OHLC, add, mul float64) {
+	c.Open = c.Open*mul + add
+	c.High = c.High*mul + add
+	c.Low = c.Low*mul + add
+	c.Close = c.Close*mul + add
+}
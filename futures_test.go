@@ -0,0 +1,204 @@
+package exante
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func day(d int) Timestamp {
+	return Timestamp{time.Date(2018, time.January, d, 0, 0, 0, 0, time.UTC)}
+}
+
+func dailyCandles(from int, closes ...float64) []OHLC {
+	res := make([]OHLC, len(closes))
+	for i, c := range closes {
+		res[i] = OHLC{Timestamp: day(from + i), Open: c, High: c + 1, Low: c - 1, Close: c}
+	}
+	return res
+}
+
+func closes(candles []OHLC) []float64 {
+	res := make([]float64, len(candles))
+	for i, c := range candles {
+		res[i] = c.Close
+	}
+	return res
+}
+
+var futuresGroup = []Symbol{
+	{ID: "6R.CME.H2018", Type: "FUTURE", Expiration: day(20)},
+	{ID: "6R.CME.G2018", Type: "FUTURE", Expiration: day(10)},
+}
+
+func TestStitchContinuous(t *testing.T) {
+	contracts := futuresContracts(futuresGroup)
+	history := map[string][]OHLC{
+		"6R.CME.G2018": dailyCandles(5, 100, 101, 102, 103),
+		"6R.CME.H2018": dailyCandles(6, 110, 111, 112, 113),
+	}
+
+	assert.Equal(t, "6R.CME.G2018", contracts[0].ID)
+	assert.Equal(t, "6R.CME.H2018", contracts[1].ID)
+
+	series, err := StitchContinuous(contracts, history, RollOptions{DaysBeforeExpiry: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{100, 101, 102, 112, 113}, closes(series))
+	assert.Equal(t, day(5), series[0].Timestamp)
+	assert.Equal(t, day(9), series[4].Timestamp)
+
+	series, err = StitchContinuous(contracts, history, RollOptions{DaysBeforeExpiry: 2, Adjustment: DifferenceAdjustment})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{109, 110, 111, 112, 113}, closes(series))
+	assert.Equal(t, 110.0, series[0].High)
+	// Source history is left untouched
+	assert.Equal(t, 100.0, history["6R.CME.G2018"][0].Close)
+
+	series, err = StitchContinuous(contracts, history, RollOptions{DaysBeforeExpiry: 2, Adjustment: RatioAdjustment})
+	require.NoError(t, err)
+	ratio := 111.0 / 102.0
+	assert.InDeltaSlice(t, []float64{100 * ratio, 101 * ratio, 111, 112, 113}, closes(series), 1e-9)
+
+	// Roll on expiration day
+	series, err = StitchContinuous(contracts, history, RollOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{100, 101, 102, 103}, closes(series))
+}
+
+func TestStitchContinuousAfterWeekend(t *testing.T) {
+	contracts := futuresContracts(futuresGroup)
+	opts := RollOptions{DaysBeforeExpiry: 2, Adjustment: DifferenceAdjustment}
+	// No trading on day 7, the day before the roll
+	history := map[string][]OHLC{
+		"6R.CME.G2018": dailyCandles(5, 100, 101),
+		"6R.CME.H2018": append(dailyCandles(5, 110, 111), dailyCandles(8, 112, 113)...),
+	}
+	series, err := StitchContinuous(contracts, history, opts)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{110, 111, 112, 113}, closes(series))
+
+	history["6R.CME.H2018"] = dailyCandles(8, 112, 113)
+	_, err = StitchContinuous(contracts, history, opts)
+	assert.EqualError(t, err, "no price of 6R.CME.H2018 at the roll from 6R.CME.G2018 on 2018-01-06T00:00:00Z")
+}
+
+func TestContinuousOHLC(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/groups/6R").
+		Reply(200).
+		BodyString(`[
+			{"id":"6R.CME.H2018","type":"FUTURE","group":"6R","expiration":1516406400000},
+			{"id":"6R.CME.G2018","type":"FUTURE","group":"6R","expiration":1515542400000}
+	]`)
+	gock.New(baseUrl).
+		Get("/ohlc/6R.CME.G2018/86400").
+		Reply(200).
+		BodyString(`[
+			{"timestamp":1515369600000,"open":103,"high":103,"low":103,"close":103},
+			{"timestamp":1515283200000,"open":102,"high":102,"low":102,"close":102},
+			{"timestamp":1515196800000,"open":101,"high":101,"low":101,"close":101},
+			{"timestamp":1515110400000,"open":100,"high":100,"low":100,"close":100}
+	]`)
+	gock.New(baseUrl).
+		Get("/ohlc/6R.CME.H2018/86400").
+		MatchParam("from", "1515283200000").
+		Reply(200).
+		BodyString(`[
+			{"timestamp":1515456000000,"open":113,"high":113,"low":113,"close":113},
+			{"timestamp":1515369600000,"open":112,"high":112,"low":112,"close":112},
+			{"timestamp":1515283200000,"open":111,"high":111,"low":111,"close":111}
+	]`)
+
+	from := day(5).Time
+	to := day(9).Time
+	series, err := NewClient("", "", "").ContinuousOHLC("6R", Duration1Day, from, to,
+		RollOptions{DaysBeforeExpiry: 2, Adjustment: DifferenceAdjustment})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []float64{109, 110, 111, 112, 113}, closes(series))
+	assert.True(t, series[0].Timestamp.Equal(from))
+	assert.True(t, series[4].Timestamp.Equal(to))
+	assert.True(t, gock.IsDone())
+}
+
+func TestContinuousOHLCAfterWeekend(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/groups/6R").
+		Reply(200).
+		BodyString(`[
+			{"id":"6R.CME.H2018","type":"FUTURE","group":"6R","expiration":1516406400000},
+			{"id":"6R.CME.G2018","type":"FUTURE","group":"6R","expiration":1515542400000}
+	]`)
+	gock.New(baseUrl).
+		Get("/ohlc/6R.CME.G2018/86400").
+		Reply(200).
+		BodyString(`[
+			{"timestamp":1515196800000,"open":101,"high":101,"low":101,"close":101},
+			{"timestamp":1515110400000,"open":100,"high":100,"low":100,"close":100}
+	]`)
+	// Fetched from the last G2018 candle on day 6 rather than from day 7
+	gock.New(baseUrl).
+		Get("/ohlc/6R.CME.H2018/86400").
+		MatchParam("from", "1515196800000").
+		Reply(200).
+		BodyString(`[
+			{"timestamp":1515456000000,"open":113,"high":113,"low":113,"close":113},
+			{"timestamp":1515369600000,"open":112,"high":112,"low":112,"close":112},
+			{"timestamp":1515196800000,"open":111,"high":111,"low":111,"close":111}
+	]`)
+
+	series, err := NewClient("", "", "").ContinuousOHLC("6R", Duration1Day, day(5).Time, day(9).Time,
+		RollOptions{DaysBeforeExpiry: 2, Adjustment: DifferenceAdjustment})
+
+	require.NoError(t, err)
+	assert.Equal(t, []float64{110, 111, 112, 113}, closes(series))
+	assert.True(t, gock.IsDone())
+}
+
+func TestContinuousOHLCPages(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/groups/6R").
+		Reply(200).
+		BodyString(`[{"id":"6R.CME.H2018","type":"FUTURE","group":"6R","expiration":1516406400000}]`)
+	// Two candles per page from the day before the period
+	pages := [][]string{
+		{"1514678400000", "1514851199000", `[{"timestamp":1514764800000,"close":101},{"timestamp":1514678400000,"close":100}]`},
+		{"1514851200000", "1515023999000", `[{"timestamp":1514937600000,"close":103},{"timestamp":1514851200000,"close":102}]`},
+		{"1515024000000", "1515110400000", `[{"timestamp":1515110400000,"close":105},{"timestamp":1515024000000,"close":104}]`},
+	}
+	for _, p := range pages {
+		gock.New(baseUrl).
+			Get("/ohlc/6R.CME.H2018/86400").
+			MatchParam("from", p[0]).
+			MatchParam("to", p[1]).
+			MatchParam("size", "2").
+			Reply(200).
+			BodyString(p[2])
+	}
+
+	series, err := NewClient("", "", "").ContinuousOHLC("6R", Duration1Day, day(1).Time, day(5).Time,
+		RollOptions{PageSize: 2})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []float64{101, 102, 103, 104, 105}, closes(series))
+	assert.True(t, gock.IsDone())
+
+	_, err = NewClient("", "", "").ContinuousOHLC("6R", Duration1Day, day(1).Time, day(5).Time,
+		RollOptions{PageSize: -1})
+	assert.Error(t, err)
+	_, err = NewClient("", "", "").ContinuousOHLC("6R", 0, day(1).Time, day(5).Time, RollOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,362 @@
+package exante
+
+import (
+	"sort"
+	"strings"
+)
+
+// SymbolIndex provides fast lookups and search over a list of symbols,
+// typically the result of Client.Symbols.
+type SymbolIndex struct {
+	symbols []Symbol
+	byID    map[string]int
+
+	byExchange map[string][]int
+	byType     map[SymbolType][]int
+	byGroup    map[string][]int
+	byCurrency map[string][]int
+	byCountry  map[string][]int
+
+	// Lower-cased Ticker, Name and Description sorted for prefix search
+	prefixes []indexKey
+}
+
+type indexKey struct {
+	key string
+	pos int
+}
+
+func NewSymbolIndex(symbols []Symbol) *SymbolIndex {
+	idx := &SymbolIndex{
+		symbols:    symbols,
+		byID:       make(map[string]int, len(symbols)),
+		byExchange: make(map[string][]int),
+		byType:     make(map[SymbolType][]int),
+		byGroup:    make(map[string][]int),
+		byCurrency: make(map[string][]int),
+		byCountry:  make(map[string][]int),
+		prefixes:   make([]indexKey, 0, 3*len(symbols)),
+	}
+	for i := range symbols {
+		s := &symbols[i]
+		idx.byID[s.ID] = i
+		idx.byExchange[s.Exchange] = append(idx.byExchange[s.Exchange], i)
+		idx.byType[s.Type] = append(idx.byType[s.Type], i)
+		idx.byGroup[s.Group] = append(idx.byGroup[s.Group], i)
+		idx.byCurrency[s.Currency] = append(idx.byCurrency[s.Currency], i)
+		idx.byCountry[s.Country] = append(idx.byCountry[s.Country], i)
+		for _, text := range []string{s.Ticker, s.Name, s.Description} {
+			if text != "" {
+				idx.prefixes = append(idx.prefixes, indexKey{strings.ToLower(text), i})
+			}
+		}
+	}
+	sort.Slice(idx.prefixes, func(i, j int) bool {
+		if idx.prefixes[i].key != idx.prefixes[j].key {
+			return idx.prefixes[i].key < idx.prefixes[j].key
+		}
+		return idx.prefixes[i].pos < idx.prefixes[j].pos
+	})
+	return idx
+}
+
+func (idx *SymbolIndex) Len() int {
+	return len(idx.symbols)
+}
+
+// Get returns the symbol with the given ID.
+func (idx *SymbolIndex) Get(id string) (*Symbol, bool) {
+	i, ok := idx.byID[id]
+	if !ok {
+		return nil, false
+	}
+	return &idx.symbols[i], true
+}
+
+// Query starts a new query matching all symbols of the index.
+func (idx *SymbolIndex) Query() *SymbolQuery {
+	return &SymbolQuery{idx: idx}
+}
+
+// PrefixSearch returns symbols whose Ticker, Name or Description starts
+// with prefix, ignoring case, in index order. limit <= 0 means no limit.
+func (idx *SymbolIndex) PrefixSearch(prefix string, limit int) []Symbol {
+	prefix = strings.ToLower(prefix)
+	start := sort.Search(len(idx.prefixes), func(i int) bool {
+		return idx.prefixes[i].key >= prefix
+	})
+	seen := make(map[int]bool)
+	var positions []int
+	for _, k := range idx.prefixes[start:] {
+		if !strings.HasPrefix(k.key, prefix) {
+			break
+		}
+		if !seen[k.pos] {
+			seen[k.pos] = true
+			positions = append(positions, k.pos)
+		}
+	}
+	sort.Ints(positions)
+	return idx.collect(positions, limit)
+}
+
+type SymbolMatch struct {
+	Symbol Symbol
+	Score  float64
+}
+
+// Search performs a fuzzy case-insensitive search over Ticker, Name and
+// Description and returns matches ordered by decreasing score. Ticker
+// matches rank above Name matches, which rank above Description ones.
+// limit <= 0 means no limit.
+func (idx *SymbolIndex) Search(query string, limit int) []SymbolMatch {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+	var matches []SymbolMatch
+	for i := range idx.symbols {
+		s := &idx.symbols[i]
+		score := 3 * fuzzyScore(query, strings.ToLower(s.Ticker))
+		if v := 2 * fuzzyScore(query, strings.ToLower(s.Name)); v > score {
+			score = v
+		}
+		if v := fuzzyScore(query, strings.ToLower(s.Description)); v > score {
+			score = v
+		}
+		if score > 0 {
+			matches = append(matches, SymbolMatch{Symbol: *s, Score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+func (idx *SymbolIndex) collect(positions []int, limit int) []Symbol {
+	if limit > 0 && len(positions) > limit {
+		positions = positions[:limit]
+	}
+	res := make([]Symbol, len(positions))
+	for i, pos := range positions {
+		res[i] = idx.symbols[pos]
+	}
+	return res
+}
+
+// SymbolQuery is a composable filter over a SymbolIndex. Each method adds
+// a condition and all conditions must hold. Methods taking several values
+// match any of them.
+type SymbolQuery struct {
+	idx        *SymbolIndex
+	candidates []int // smallest secondary index selection, nil for all
+	filters    []func(*Symbol) bool
+	limit      int
+}
+
+func (q *SymbolQuery) Exchange(exchanges ...string) *SymbolQuery {
+	return q.indexed(q.idx.byExchange, exchanges, func(s *Symbol) string { return s.Exchange })
+}
+
+func (q *SymbolQuery) Type(types ...SymbolType) *SymbolQuery {
+	var positions []int
+	set := make(map[SymbolType]bool, len(types))
+	for _, t := range types {
+		if !set[t] {
+			positions = append(positions, q.idx.byType[t]...)
+			set[t] = true
+		}
+	}
+	q.narrow(positions)
+	return q.Where(func(s *Symbol) bool { return set[s.Type] })
+}
+
+func (q *SymbolQuery) Group(groups ...string) *SymbolQuery {
+	return q.indexed(q.idx.byGroup, groups, func(s *Symbol) string { return s.Group })
+}
+
+func (q *SymbolQuery) Currency(currencies ...string) *SymbolQuery {
+	return q.indexed(q.idx.byCurrency, currencies, func(s *Symbol) string { return s.Currency })
+}
+
+func (q *SymbolQuery) Country(countries ...string) *SymbolQuery {
+	return q.indexed(q.idx.byCountry, countries, func(s *Symbol) string { return s.Country })
+}
+
+// Prefix keeps symbols whose Ticker, Name or Description starts with
+// prefix, ignoring case.
+func (q *SymbolQuery) Prefix(prefix string) *SymbolQuery {
+	prefix = strings.ToLower(prefix)
+	return q.Where(func(s *Symbol) bool {
+		return strings.HasPrefix(strings.ToLower(s.Ticker), prefix) ||
+			strings.HasPrefix(strings.ToLower(s.Name), prefix) ||
+			strings.HasPrefix(strings.ToLower(s.Description), prefix)
+	})
+}
+
+// Contains keeps symbols whose Ticker, Name or Description contains text,
+// ignoring case.
+func (q *SymbolQuery) Contains(text string) *SymbolQuery {
+	text = strings.ToLower(text)
+	return q.Where(func(s *Symbol) bool {
+		return strings.Contains(strings.ToLower(s.Ticker), text) ||
+			strings.Contains(strings.ToLower(s.Name), text) ||
+			strings.Contains(strings.ToLower(s.Description), text)
+	})
+}
+
+// Where adds an arbitrary condition.
+func (q *SymbolQuery) Where(fn func(*Symbol) bool) *SymbolQuery {
+	q.filters = append(q.filters, fn)
+	return q
+}
+
+func (q *SymbolQuery) Limit(n int) *SymbolQuery {
+	q.limit = n
+	return q
+}
+
+// All returns the matching symbols in index order.
+func (q *SymbolQuery) All() []Symbol {
+	return q.idx.collect(q.positions(q.limit), 0)
+}
+
+func (q *SymbolQuery) Count() int {
+	return len(q.positions(q.limit))
+}
+
+func (q *SymbolQuery) First() (*Symbol, bool) {
+	positions := q.positions(1)
+	if len(positions) == 0 {
+		return nil, false
+	}
+	return &q.idx.symbols[positions[0]], true
+}
+
+func (q *SymbolQuery) indexed(index map[string][]int, values []string, field func(*Symbol) string) *SymbolQuery {
+	var positions []int
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		// Repeated values would select their symbols twice
+		if !set[v] {
+			positions = append(positions, index[v]...)
+			set[v] = true
+		}
+	}
+	q.narrow(positions)
+	return q.Where(func(s *Symbol) bool { return set[field(s)] })
+}
+
+// narrow makes positions the query candidates if it is the smallest
+// secondary index selection so far.
+func (q *SymbolQuery) narrow(positions []int) {
+	sort.Ints(positions)
+	if q.candidates == nil || len(positions) < len(q.candidates) {
+		q.candidates = positions
+		if q.candidates == nil {
+			q.candidates = []int{}
+		}
+	}
+}
+
+func (q *SymbolQuery) positions(limit int) []int {
+	candidates := q.candidates
+	if candidates == nil {
+		candidates = make([]int, len(q.idx.symbols))
+		for i := range candidates {
+			candidates[i] = i
+		}
+	}
+	var res []int
+	for _, pos := range candidates {
+		if q.match(&q.idx.symbols[pos]) {
+			res = append(res, pos)
+			if limit > 0 && len(res) == limit {
+				break
+			}
+		}
+	}
+	return res
+}
+
+func (q *SymbolQuery) match(s *Symbol) bool {
+	for _, fn := range q.filters {
+		if !fn(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// fuzzyScore rates how well query matches text, both lower-cased, in the
+// range [0, 1]. Exact and prefix matches score highest, followed by word
+// prefixes, substrings, near misses and finally ordered subsequences.
+func fuzzyScore(query, text string) float64 {
+	switch {
+	case text == "":
+		return 0
+	case text == query:
+		return 1
+	case strings.HasPrefix(text, query):
+		return 0.9
+	}
+	for _, word := range strings.FieldsFunc(text, isSeparator) {
+		if strings.HasPrefix(word, query) {
+			return 0.8
+		}
+	}
+	if strings.Contains(text, query) {
+		return 0.6
+	}
+	if len(query) >= 3 {
+		for _, word := range strings.FieldsFunc(text, isSeparator) {
+			if levenshtein(query, word) == 1 {
+				return 0.5
+			}
+		}
+	}
+	// Ordered subsequence, scored by how compact the match is
+	qi, first, last := 0, -1, -1
+	for i := 0; i < len(text) && qi < len(query); i++ {
+		if text[i] == query[qi] {
+			if first < 0 {
+				first = i
+			}
+			last = i
+			qi++
+		}
+	}
+	if qi < len(query) {
+		return 0
+	}
+	return 0.4 * float64(len(query)) / float64(last-first+1)
+}
+
+func isSeparator(r rune) bool {
+	return r == ' ' || r == '.' || r == '/' || r == '-' || r == ','
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
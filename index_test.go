@@ -0,0 +1,101 @@
+package exante
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var indexSymbols = []Symbol{
+	{ID: "AAPL.NASDAQ", Name: "Apple", Ticker: "AAPL", Type: "STOCK", Description: "Apple",
+		Exchange: "NASDAQ", Country: "US", Currency: "USD"},
+	{ID: "GOOG.NASDAQ", Name: "Alphabet Class C", Ticker: "GOOG", Type: "STOCK", Description: "Alphabet Class C",
+		Exchange: "NASDAQ", Country: "US", Currency: "USD"},
+	{ID: "USD/RUB.EXANTE", Ticker: "USD/RUB", Type: "CURRENCY", Description: "USD/RUB", Currency: "RUB"},
+	{ID: "6R.CME.M2018", Name: "RUB/USD", Ticker: "6R", Type: "FUTURE", Description: "Futures On RUB/USD Jun 2018",
+		Exchange: "CME", Country: "US", Currency: "USD", Group: "6R"},
+	{ID: "SPX.CBOE.16M2017.P2250", Name: "S&P 500 Index", Ticker: "SPX", Type: "OPTION",
+		Description: "Options On S&P 500 Index 16 Jun 2017 PUT 2250", Exchange: "CBOE", Country: "US",
+		Currency: "USD", Group: "SPX.CBOE"},
+	{ID: "BK.NYSE", Name: "Bank Of New York Mellon Corporation", Ticker: "BK", Type: "STOCK",
+		Description: "Bank Of New York Mellon Corporation", Exchange: "NYSE", Country: "US", Currency: "USD"},
+}
+
+func ids(symbols []Symbol) []string {
+	res := make([]string, len(symbols))
+	for i, s := range symbols {
+		res[i] = s.ID
+	}
+	return res
+}
+
+func TestSymbolIndexGet(t *testing.T) {
+	idx := NewSymbolIndex(indexSymbols)
+
+	assert.Equal(t, 6, idx.Len())
+	s, ok := idx.Get("6R.CME.M2018")
+	assert.True(t, ok)
+	assert.Equal(t, "RUB/USD", s.Name)
+	_, ok = idx.Get("6R.CME.Z2018")
+	assert.False(t, ok)
+}
+
+func TestSymbolIndexQuery(t *testing.T) {
+	idx := NewSymbolIndex(indexSymbols)
+
+	assert.Equal(t, []string{"AAPL.NASDAQ", "GOOG.NASDAQ", "BK.NYSE"},
+		ids(idx.Query().Type("STOCK").All()))
+	assert.Equal(t, []string{"AAPL.NASDAQ", "GOOG.NASDAQ"},
+		ids(idx.Query().Type("STOCK").Exchange("NASDAQ").All()))
+	assert.Equal(t, []string{"6R.CME.M2018", "SPX.CBOE.16M2017.P2250"},
+		ids(idx.Query().Type("FUTURE", "OPTION").Country("US").All()))
+	assert.Equal(t, []string{"USD/RUB.EXANTE"}, ids(idx.Query().Currency("RUB").All()))
+	assert.Equal(t, []string{"SPX.CBOE.16M2017.P2250"}, ids(idx.Query().Group("SPX.CBOE").All()))
+	assert.Equal(t, []string{"6R.CME.M2018"},
+		ids(idx.Query().Contains("rub/usd").All()))
+	assert.Equal(t, 2, idx.Query().Contains("rub").Count())
+	assert.Equal(t, 0, idx.Query().Exchange("LSE").Count())
+	assert.Equal(t, []string{"AAPL.NASDAQ", "GOOG.NASDAQ"},
+		ids(idx.Query().Exchange("NASDAQ", "NASDAQ").All()), "repeated values")
+	assert.Equal(t, 3, idx.Query().Type("STOCK", "STOCK").Count())
+	assert.Equal(t, []string{"AAPL.NASDAQ"}, ids(idx.Query().Exchange("NASDAQ").Limit(1).All()))
+
+	s, ok := idx.Query().Type("STOCK").Prefix("alpha").First()
+	assert.True(t, ok)
+	assert.Equal(t, "GOOG.NASDAQ", s.ID)
+
+	custom := idx.Query().Where(func(s *Symbol) bool { return s.Group != "" }).All()
+	assert.Equal(t, []string{"6R.CME.M2018", "SPX.CBOE.16M2017.P2250"}, ids(custom))
+}
+
+func TestSymbolIndexPrefixSearch(t *testing.T) {
+	idx := NewSymbolIndex(indexSymbols)
+
+	assert.Equal(t, []string{"USD/RUB.EXANTE"}, ids(idx.PrefixSearch("usd", 0)))
+	assert.Equal(t, []string{"AAPL.NASDAQ", "GOOG.NASDAQ"}, ids(idx.PrefixSearch("A", 0)))
+	assert.Equal(t, []string{"AAPL.NASDAQ"}, ids(idx.PrefixSearch("A", 1)))
+	assert.Empty(t, idx.PrefixSearch("xyz", 0))
+}
+
+func TestSymbolIndexSearch(t *testing.T) {
+	idx := NewSymbolIndex(indexSymbols)
+
+	matches := idx.Search("spx", 0)
+	assert.Equal(t, "SPX.CBOE.16M2017.P2250", matches[0].Symbol.ID)
+
+	matches = idx.Search("mellon", 0)
+	assert.Equal(t, 1, len(matches))
+	assert.Equal(t, "BK.NYSE", matches[0].Symbol.ID)
+
+	// Typo in a word
+	matches = idx.Search("alphabat", 0)
+	assert.Equal(t, "GOOG.NASDAQ", matches[0].Symbol.ID)
+
+	// Ticker match ranks above description match
+	matches = idx.Search("aapl", 2)
+	assert.Equal(t, "AAPL.NASDAQ", matches[0].Symbol.ID)
+	assert.True(t, len(matches) <= 2)
+
+	assert.Empty(t, idx.Search("qqqq", 0))
+	assert.Empty(t, idx.Search(" ", 0))
+}
@@ -0,0 +1,172 @@
+package exante
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// SymbolSource provides reference data about symbols, exchanges and groups.
+type SymbolSource interface {
+	Symbols() ([]Symbol, error)
+	Symbol(id string) (*Symbol, error)
+	SymbolSpecification(id string) (*SymbolSpecification, error)
+	SymbolSchedule(id string) ([]SymbolScheduleInterval, error)
+	Exchanges() ([]Exchange, error)
+	ExchangeSymbols(id string) ([]Symbol, error)
+	Types() ([]SymbolType, error)
+	TypeSymbols(id SymbolType) ([]Symbol, error)
+	Groups() ([]Group, error)
+	GroupSymbols(id string) ([]Symbol, error)
+	GroupNearestSymbol(id string) (*Symbol, error)
+}
+
+// OHLCSource provides historical candles.
+type OHLCSource interface {
+	OHLC(symbolId string, duration Duration, from time.Time, to time.Time, size int) ([]OHLC, error)
+}
+
+// MarketData is implemented by Client and by the decorators wrapping it, so
+// that callers can depend on the interface and swap or mock the client.
+type MarketData interface {
+	SymbolSource
+	OHLCSource
+}
+
+var (
+	_ MarketData   = (*Client)(nil)
+	_ SymbolSource = (*Cache)(nil)
+	_ MarketData   = (*CachingMarketData)(nil)
+)
+
+// CachingMarketData serves reference data of a MarketData from a Cache.
+// Candles are passed through uncached. Callers must Close it so that
+// batched writes of the cache file are not lost.
+type CachingMarketData struct {
+	*Cache
+	OHLCSource
+}
+
+// NewCachingMarketData wraps md with a Cache configured by opts.
+func NewCachingMarketData(md MarketData, opts CacheOptions) (*CachingMarketData, error) {
+	cache, err := NewCache(md, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &CachingMarketData{Cache: cache, OHLCSource: md}, nil
+}
+
+// LoggingMarketData logs every call to md with its arguments, result size
+// and latency. Unlike LoggingMiddleware it also sees calls that are served
+// without a request, e.g. by a cache.
+func LoggingMarketData(md MarketData, logger *slog.Logger) MarketData {
+	return &loggingMarketData{next: md, logger: logger}
+}
+
+type loggingMarketData struct {
+	next   MarketData
+	logger *slog.Logger
+}
+
+func (l *loggingMarketData) log(method string, start time.Time, count int, err error, attrs ...slog.Attr) {
+	attrs = append(attrs,
+		slog.String("method", method),
+		slog.Duration("latency", time.Since(start)),
+	)
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+		l.logger.LogAttrs(context.Background(), slog.LevelError, "exante call failed", attrs...)
+		return
+	}
+	attrs = append(attrs, slog.Int("count", count))
+	l.logger.LogAttrs(context.Background(), slog.LevelDebug, "exante call", attrs...)
+}
+
+func (l *loggingMarketData) Symbols() ([]Symbol, error) {
+	start := time.Now()
+	symbols, err := l.next.Symbols()
+	l.log("Symbols", start, len(symbols), err)
+	return symbols, err
+}
+
+func (l *loggingMarketData) Symbol(id string) (*Symbol, error) {
+	start := time.Now()
+	symbol, err := l.next.Symbol(id)
+	l.log("Symbol", start, 1, err, slog.String("id", id))
+	return symbol, err
+}
+
+func (l *loggingMarketData) SymbolSpecification(id string) (*SymbolSpecification, error) {
+	start := time.Now()
+	spec, err := l.next.SymbolSpecification(id)
+	l.log("SymbolSpecification", start, 1, err, slog.String("id", id))
+	return spec, err
+}
+
+func (l *loggingMarketData) SymbolSchedule(id string) ([]SymbolScheduleInterval, error) {
+	start := time.Now()
+	intervals, err := l.next.SymbolSchedule(id)
+	l.log("SymbolSchedule", start, len(intervals), err, slog.String("id", id))
+	return intervals, err
+}
+
+func (l *loggingMarketData) Exchanges() ([]Exchange, error) {
+	start := time.Now()
+	exchanges, err := l.next.Exchanges()
+	l.log("Exchanges", start, len(exchanges), err)
+	return exchanges, err
+}
+
+func (l *loggingMarketData) ExchangeSymbols(id string) ([]Symbol, error) {
+	start := time.Now()
+	symbols, err := l.next.ExchangeSymbols(id)
+	l.log("ExchangeSymbols", start, len(symbols), err, slog.String("id", id))
+	return symbols, err
+}
+
+func (l *loggingMarketData) Types() ([]SymbolType, error) {
+	start := time.Now()
+	types, err := l.next.Types()
+	l.log("Types", start, len(types), err)
+	return types, err
+}
+
+func (l *loggingMarketData) TypeSymbols(id SymbolType) ([]Symbol, error) {
+	start := time.Now()
+	symbols, err := l.next.TypeSymbols(id)
+	l.log("TypeSymbols", start, len(symbols), err, slog.String("id", string(id)))
+	return symbols, err
+}
+
+func (l *loggingMarketData) Groups() ([]Group, error) {
+	start := time.Now()
+	groups, err := l.next.Groups()
+	l.log("Groups", start, len(groups), err)
+	return groups, err
+}
+
+func (l *loggingMarketData) GroupSymbols(id string) ([]Symbol, error) {
+	start := time.Now()
+	symbols, err := l.next.GroupSymbols(id)
+	l.log("GroupSymbols", start, len(symbols), err, slog.String("id", id))
+	return symbols, err
+}
+
+func (l *loggingMarketData) GroupNearestSymbol(id string) (*Symbol, error) {
+	start := time.Now()
+	symbol, err := l.next.GroupNearestSymbol(id)
+	l.log("GroupNearestSymbol", start, 1, err, slog.String("id", id))
+	return symbol, err
+}
+
+func (l *loggingMarketData) OHLC(symbolId string, duration Duration, from time.Time, to time.Time, size int) ([]OHLC, error) {
+	start := time.Now()
+	candles, err := l.next.OHLC(symbolId, duration, from, to, size)
+	l.log("OHLC", start, len(candles), err,
+		slog.String("id", symbolId),
+		slog.Int("duration", int(duration)),
+		slog.Time("from", from),
+		slog.Time("to", to),
+	)
+	return candles, err
+}
@@ -0,0 +1,183 @@
+package exante
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stubMarketData serves fixed data and counts calls per method.
+type stubMarketData struct {
+	calls map[string]int
+}
+
+func (s *stubMarketData) call(method string) {
+	if s.calls == nil {
+		s.calls = make(map[string]int)
+	}
+	s.calls[method]++
+}
+
+func (s *stubMarketData) Symbols() ([]Symbol, error) {
+	s.call("Symbols")
+	return []Symbol{{ID: "AAPL.NASDAQ"}, {ID: "MSFT.NASDAQ"}}, nil
+}
+
+func (s *stubMarketData) Symbol(id string) (*Symbol, error) {
+	s.call("Symbol")
+	if id == "" {
+		return nil, errors.New("symbol not found")
+	}
+	return &Symbol{ID: id}, nil
+}
+
+func (s *stubMarketData) SymbolSpecification(id string) (*SymbolSpecification, error) {
+	s.call("SymbolSpecification")
+	return &SymbolSpecification{LotSize: 1}, nil
+}
+
+func (s *stubMarketData) SymbolSchedule(id string) ([]SymbolScheduleInterval, error) {
+	s.call("SymbolSchedule")
+	return nil, nil
+}
+
+func (s *stubMarketData) Exchanges() ([]Exchange, error) {
+	s.call("Exchanges")
+	return []Exchange{{ID: "NASDAQ"}}, nil
+}
+
+func (s *stubMarketData) ExchangeSymbols(id string) ([]Symbol, error) {
+	s.call("ExchangeSymbols")
+	return []Symbol{{ID: "AAPL." + id}}, nil
+}
+
+func (s *stubMarketData) Types() ([]SymbolType, error) {
+	s.call("Types")
+	return []SymbolType{SymbolTypeStock}, nil
+}
+
+func (s *stubMarketData) TypeSymbols(id SymbolType) ([]Symbol, error) {
+	s.call("TypeSymbols")
+	return nil, nil
+}
+
+func (s *stubMarketData) Groups() ([]Group, error) {
+	s.call("Groups")
+	return nil, nil
+}
+
+func (s *stubMarketData) GroupSymbols(id string) ([]Symbol, error) {
+	s.call("GroupSymbols")
+	return nil, nil
+}
+
+func (s *stubMarketData) GroupNearestSymbol(id string) (*Symbol, error) {
+	s.call("GroupNearestSymbol")
+	return &Symbol{ID: id + ".CME.Z2030"}, nil
+}
+
+func (s *stubMarketData) OHLC(symbolId string, duration Duration, from time.Time, to time.Time, size int) ([]OHLC, error) {
+	s.call("OHLC")
+	return []OHLC{{Close: 1}}, nil
+}
+
+func TestCachingMarketData(t *testing.T) {
+	stub := &stubMarketData{}
+	md, err := NewCachingMarketData(stub, CacheOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer md.Close()
+
+	for i := 0; i < 2; i++ {
+		symbol, err := md.Symbol("AAPL.NASDAQ")
+		assert.NoError(t, err)
+		assert.Equal(t, "AAPL.NASDAQ", symbol.ID)
+		_, err = md.SymbolSpecification("AAPL.NASDAQ")
+		assert.NoError(t, err)
+		symbols, err := md.ExchangeSymbols("NYSE")
+		assert.NoError(t, err)
+		assert.Equal(t, "AAPL.NYSE", symbols[0].ID)
+		_, err = md.GroupNearestSymbol("ES")
+		assert.NoError(t, err)
+		_, err = md.OHLC("AAPL.NASDAQ", Duration1Day, time.Time{}, time.Time{}, 0)
+		assert.NoError(t, err)
+	}
+	_, err = md.Symbol("MSFT.NASDAQ")
+	assert.NoError(t, err)
+	_, err = md.Symbol("")
+	assert.Error(t, err)
+	_, err = md.Symbol("")
+	assert.Error(t, err)
+
+	assert.Equal(t, map[string]int{
+		"Symbol":              4, // errors are not cached
+		"SymbolSpecification": 1,
+		"ExchangeSymbols":     1,
+		"GroupNearestSymbol":  2,
+		"OHLC":                2,
+	}, stub.calls)
+}
+
+func TestCachePersistenceByID(t *testing.T) {
+	path := t.TempDir() + "/exante.cache"
+	cache, err := NewCache(&stubMarketData{}, CacheOptions{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cache.Symbol("6E.CME.H2030")
+	assert.NoError(t, err)
+	_, err = cache.SymbolSpecification("6E.CME.H2030")
+	assert.NoError(t, err)
+	assert.NoError(t, cache.Close())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "per-ID entries alone are not saved")
+
+	_, err = cache.Symbols()
+	assert.NoError(t, err)
+	assert.NoError(t, cache.Close())
+
+	stub := &stubMarketData{}
+	restored, err := NewCache(stub, CacheOptions{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = restored.Symbols()
+	assert.NoError(t, err)
+	symbol, err := restored.Symbol("6E.CME.H2030")
+	assert.NoError(t, err)
+	assert.Equal(t, "6E.CME.H2030", symbol.ID)
+	assert.Equal(t, map[string]int{"Symbol": 1}, stub.calls)
+
+	assert.NoError(t, restored.Refresh())
+	assert.Equal(t, 2, stub.calls["Symbol"])
+	assert.Equal(t, 1, stub.calls["Symbols"])
+}
+
+func TestLoggingMarketData(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	md := LoggingMarketData(&stubMarketData{}, logger)
+
+	symbols, err := md.Symbols()
+	assert.NoError(t, err)
+	assert.Len(t, symbols, 2)
+	_, err = md.Symbol("")
+	assert.Error(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.Contains(t, lines[0], "level=DEBUG")
+		assert.Contains(t, lines[0], "method=Symbols")
+		assert.Contains(t, lines[0], "count=2")
+		assert.Contains(t, lines[1], "level=ERROR")
+		assert.Contains(t, lines[1], "method=Symbol")
+		assert.Contains(t, lines[1], `error="symbol not found"`)
+	}
+}
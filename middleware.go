@@ -0,0 +1,162 @@
+package exante
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Call describes a single HTTP request made by the Client.
+type Call struct {
+	Endpoint   string            // API path, e.g. "/symbols/AAPL.NASDAQ"
+	Template   string            // path template, e.g. "/symbols/{id}"
+	PathParams map[string]string // template placeholder values, e.g. "id"
+	Scope      string            // JWT audience the request is signed for
+	Attempt    int               // 0 for the first try, incremented on retries
+	Request    *http.Request
+}
+
+// Handler performs a Call and returns the raw HTTP response.
+type Handler func(call *Call) (*http.Response, error)
+
+// Middleware wraps a Handler to observe or modify API calls.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware appends middleware to the client chain. The first
+// middleware is the outermost one and sees the call first.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, mw...)
+	}
+}
+
+// LoggingMiddleware logs every API call with its endpoint, scope, status
+// and latency.
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(call *Call) (*http.Response, error) {
+			start := time.Now()
+			res, err := next(call)
+			attrs := []slog.Attr{
+				slog.String("endpoint", call.Endpoint),
+				slog.String("scope", call.Scope),
+				slog.Int("attempt", call.Attempt),
+				slog.Duration("latency", time.Since(start)),
+			}
+			ctx := call.Request.Context()
+			switch {
+			case err != nil:
+				attrs = append(attrs, slog.Any("error", err))
+				logger.LogAttrs(ctx, slog.LevelError, "exante request failed", attrs...)
+			case res.StatusCode >= 400:
+				attrs = append(attrs, slog.Int("status", res.StatusCode))
+				logger.LogAttrs(ctx, slog.LevelWarn, "exante request", attrs...)
+			default:
+				attrs = append(attrs, slog.Int("status", res.StatusCode))
+				logger.LogAttrs(ctx, slog.LevelInfo, "exante request", attrs...)
+			}
+			return res, err
+		}
+	}
+}
+
+// Metrics aggregates call counts and latencies per endpoint template, so
+// that e.g. all symbol lookups share one entry. It implements
+// slog.LogValuer, so a snapshot can be logged as a single attribute.
+type Metrics struct {
+	mu    sync.Mutex
+	stats map[string]*EndpointStats
+}
+
+type EndpointStats struct {
+	Endpoint     string // path template, e.g. "/symbols/{id}"
+	Scope        string
+	Calls        int
+	Errors       int // transport errors and non-2xx responses
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+	LastStatus   int
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{stats: make(map[string]*EndpointStats)}
+}
+
+// Middleware returns a middleware feeding the collected metrics.
+func (m *Metrics) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(call *Call) (*http.Response, error) {
+			start := time.Now()
+			res, err := next(call)
+			status := 0
+			if res != nil {
+				status = res.StatusCode
+			}
+			m.record(call, status, err != nil, time.Since(start))
+			return res, err
+		}
+	}
+}
+
+func (m *Metrics) record(call *Call, status int, failed bool, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := call.Template
+	if key == "" {
+		key = call.Endpoint
+	}
+	s, ok := m.stats[key]
+	if !ok {
+		s = &EndpointStats{Endpoint: key, Scope: call.Scope}
+		m.stats[key] = s
+	}
+	s.Calls++
+	if failed || status < 200 || status >= 300 {
+		s.Errors++
+	}
+	s.TotalLatency += latency
+	if latency > s.MaxLatency {
+		s.MaxLatency = latency
+	}
+	s.LastStatus = status
+}
+
+// Snapshot returns a copy of the collected stats ordered by endpoint.
+func (m *Metrics) Snapshot() []EndpointStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := make([]EndpointStats, 0, len(m.stats))
+	for _, s := range m.stats {
+		res = append(res, *s)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Endpoint < res[j].Endpoint })
+	return res
+}
+
+func (m *Metrics) LogValue() slog.Value {
+	snapshot := m.Snapshot()
+	attrs := make([]slog.Attr, len(snapshot))
+	for i, s := range snapshot {
+		var avg time.Duration
+		if s.Calls > 0 {
+			avg = s.TotalLatency / time.Duration(s.Calls)
+		}
+		attrs[i] = slog.Group(s.Endpoint,
+			slog.String("scope", s.Scope),
+			slog.Int("calls", s.Calls),
+			slog.Int("errors", s.Errors),
+			slog.Duration("avg_latency", avg),
+			slog.Duration("max_latency", s.MaxLatency),
+			slog.Int("last_status", s.LastStatus),
+		)
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Log writes the current snapshot to logger at info level.
+func (m *Metrics) Log(ctx context.Context, logger *slog.Logger) {
+	logger.LogAttrs(ctx, slog.LevelInfo, "exante metrics", slog.Any("endpoints", m))
+}
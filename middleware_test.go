@@ -0,0 +1,139 @@
+package exante
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestMiddlewareChain(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/types").
+		MatchHeader("X-Correlation-ID", "abc").
+		Reply(200).
+		BodyString(`[{"id": "STOCK"}]`)
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(call *Call) (*http.Response, error) {
+				order = append(order, name)
+				call.Request.Header.Set("X-Correlation-ID", "abc")
+				res, err := next(call)
+				order = append(order, name+":"+call.Endpoint+":"+call.Scope)
+				return res, err
+			}
+		}
+	}
+
+	client := NewClient("", "", "", WithMiddleware(trace("outer"), trace("inner")))
+	_, err := client.Types()
+
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Equal(t, []string{"outer", "inner", "inner:/types:symbols", "outer:/types:symbols"}, order)
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/symbols/UNKNOWN").
+		Reply(404).
+		BodyString(`{"message":"not found"}`)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	_, err := NewClient("", "", "", WithMiddleware(LoggingMiddleware(logger))).Symbol("UNKNOWN")
+
+	assert.Error(t, err)
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "/symbols/UNKNOWN", record["endpoint"])
+	assert.Equal(t, "symbols", record["scope"])
+	assert.Equal(t, 404.0, record["status"])
+	assert.Contains(t, record, "latency")
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/types").
+		Times(2).
+		Reply(200).
+		BodyString(`[]`)
+	gock.New(baseUrl).
+		Get("/groups").
+		Reply(500).
+		BodyString(`internal error`)
+	gock.New(baseUrl).
+		Get("/symbols/").
+		Times(2).
+		Reply(200).
+		BodyString(`{}`)
+
+	metrics := NewMetrics()
+	client := NewClient("", "", "", WithMiddleware(metrics.Middleware()))
+	client.Types()
+	client.Types()
+	client.Groups()
+	client.Symbol("AAPL.NASDAQ")
+	client.Symbol("MSFT.NASDAQ")
+
+	stats := metrics.Snapshot()
+	assert.Equal(t, 3, len(stats))
+	assert.Equal(t, "/groups", stats[0].Endpoint)
+	assert.Equal(t, 1, stats[0].Calls)
+	assert.Equal(t, 1, stats[0].Errors)
+	assert.Equal(t, 500, stats[0].LastStatus)
+	assert.Equal(t, "/symbols/{id}", stats[1].Endpoint)
+	assert.Equal(t, 2, stats[1].Calls)
+	assert.Equal(t, "/types", stats[2].Endpoint)
+	assert.Equal(t, 2, stats[2].Calls)
+	assert.Equal(t, 0, stats[2].Errors)
+
+	var buf bytes.Buffer
+	metrics.Log(context.Background(), slog.New(slog.NewJSONHandler(&buf, nil)))
+	assert.Contains(t, buf.String(), `"/types":{"scope":"symbols","calls":2,"errors":0`)
+}
+
+func TestCallTemplate(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/ohlc/AAPL.NASDAQ/60").
+		Reply(200).
+		BodyString(`[]`)
+
+	var call *Call
+	capture := func(next Handler) Handler {
+		return func(c *Call) (*http.Response, error) {
+			call = c
+			return next(c)
+		}
+	}
+	client := NewClient("", "", "", WithMiddleware(capture))
+	_, err := client.OHLC("AAPL.NASDAQ", Duration1Minute, time.Unix(0, 0), time.Unix(60, 0), 1)
+
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Equal(t, "/ohlc/AAPL.NASDAQ/60", call.Endpoint)
+	assert.Equal(t, "/ohlc/{id}/{duration}", call.Template)
+	assert.Equal(t, map[string]string{"id": "AAPL.NASDAQ", "duration": "60"}, call.PathParams)
+	assert.Equal(t, "ohlc", call.Scope)
+}
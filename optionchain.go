@@ -0,0 +1,152 @@
+package exante
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// OptionChain is the set of options of a group arranged by expiration and
+// strike.
+type OptionChain struct {
+	Group       string
+	Expirations []OptionExpiration // ordered by expiration
+}
+
+type OptionExpiration struct {
+	Expiration Timestamp
+	Strikes    []OptionStrike // ordered by strike
+}
+
+// OptionStrike holds the call and put of a strike side by side. Either of
+// them may be nil when only one side is listed.
+type OptionStrike struct {
+	Strike float64
+	Call   *Symbol
+	Put    *Symbol
+}
+
+// OptionChain fetches the symbols of an option group and builds its chain.
+func (c *Client) OptionChain(groupID string) (*OptionChain, error) {
+	symbols, err := c.GroupSymbols(groupID)
+	if err != nil {
+		return nil, err
+	}
+	return NewOptionChain(groupID, symbols), nil
+}
+
+// NewOptionChain builds a chain from option symbols. Symbols without a
+// put or call right are ignored.
+func NewOptionChain(group string, symbols []Symbol) *OptionChain {
+	byExpiration := make(map[int64]map[float64]*OptionStrike)
+	expirations := make(map[int64]Timestamp)
+	for i := range symbols {
+		s := &symbols[i]
+		if !s.OptionData.Right.IsKnown() {
+			continue
+		}
+		key := s.Expiration.Unix()
+		strikes, ok := byExpiration[key]
+		if !ok {
+			strikes = make(map[float64]*OptionStrike)
+			byExpiration[key] = strikes
+			expirations[key] = s.Expiration
+		}
+		strike, ok := strikes[s.OptionData.StrikePrice]
+		if !ok {
+			strike = &OptionStrike{Strike: s.OptionData.StrikePrice}
+			strikes[s.OptionData.StrikePrice] = strike
+		}
+		if s.OptionData.Right == OptionCall {
+			strike.Call = s
+		} else {
+			strike.Put = s
+		}
+	}
+
+	chain := &OptionChain{Group: group}
+	for key, strikes := range byExpiration {
+		exp := OptionExpiration{Expiration: expirations[key]}
+		for _, strike := range strikes {
+			exp.Strikes = append(exp.Strikes, *strike)
+		}
+		sort.Slice(exp.Strikes, func(i, j int) bool {
+			return exp.Strikes[i].Strike < exp.Strikes[j].Strike
+		})
+		chain.Expirations = append(chain.Expirations, exp)
+	}
+	sort.Slice(chain.Expirations, func(i, j int) bool {
+		return chain.Expirations[i].Expiration.Before(chain.Expirations[j].Expiration.Time)
+	})
+	return chain
+}
+
+// Nearest returns the first expiration not before t.
+func (oc *OptionChain) Nearest(t time.Time) (*OptionExpiration, bool) {
+	i := sort.Search(len(oc.Expirations), func(i int) bool {
+		return !oc.Expirations[i].Expiration.Before(t)
+	})
+	if i == len(oc.Expirations) {
+		return nil, false
+	}
+	return &oc.Expirations[i], true
+}
+
+// Expiration returns the expiration falling on the same calendar day as t
+// in t's location.
+func (oc *OptionChain) Expiration(t time.Time) (*OptionExpiration, bool) {
+	y, m, d := t.Date()
+	for i := range oc.Expirations {
+		ey, em, ed := oc.Expirations[i].Expiration.In(t.Location()).Date()
+		if ey == y && em == m && ed == d {
+			return &oc.Expirations[i], true
+		}
+	}
+	return nil, false
+}
+
+// Strike returns the strike with the exact price.
+func (e *OptionExpiration) Strike(price float64) (*OptionStrike, bool) {
+	i := sort.Search(len(e.Strikes), func(i int) bool {
+		return e.Strikes[i].Strike >= price
+	})
+	if i == len(e.Strikes) || e.Strikes[i].Strike != price {
+		return nil, false
+	}
+	return &e.Strikes[i], true
+}
+
+// ATM returns the at-the-money strike, the one closest to the reference
+// price. Ties resolve to the lower strike.
+func (e *OptionExpiration) ATM(price float64) (*OptionStrike, bool) {
+	if len(e.Strikes) == 0 {
+		return nil, false
+	}
+	best := 0
+	for i := range e.Strikes {
+		if math.Abs(e.Strikes[i].Strike-price) < math.Abs(e.Strikes[best].Strike-price) {
+			best = i
+		}
+	}
+	return &e.Strikes[best], true
+}
+
+// StrikesAround returns up to n strikes below the reference price and up
+// to n strikes at or above it, or nil if n is not positive. The result is a
+// copy, so changing it leaves the expiration intact.
+func (e *OptionExpiration) StrikesAround(price float64, n int) []OptionStrike {
+	if n <= 0 {
+		return nil
+	}
+	i := sort.Search(len(e.Strikes), func(i int) bool {
+		return e.Strikes[i].Strike >= price
+	})
+	lo, hi := i-n, i+n
+	if lo < 0 {
+		lo = 0
+	}
+	if hi > len(e.Strikes) {
+		hi = len(e.Strikes)
+	}
+	return append([]OptionStrike(nil), e.Strikes[lo:hi]...)
+}
@@ -0,0 +1,125 @@
+package exante
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestOptionChain(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/groups/SPX.CBOE").
+		Reply(200).
+		BodyString(`[
+			{"id":"SPX.CBOE.21J2017.C2300","ticker":"SPX","type":"OPTION","group":"SPX.CBOE",
+				"expiration":1492808400000,"optionData":{"right":"CALL","strikePrice":2300}},
+			{"id":"SPX.CBOE.16M2017.P2250","ticker":"SPX","type":"OPTION","group":"SPX.CBOE",
+				"expiration":1497626100000,"optionData":{"right":"PUT","strikePrice":2250}},
+			{"id":"SPX.CBOE.16M2017.C2300","ticker":"SPX","type":"OPTION","group":"SPX.CBOE",
+				"expiration":1497626100000,"optionData":{"right":"CALL","strikePrice":2300}},
+			{"id":"SPX.CBOE.16M2017.C2250","ticker":"SPX","type":"OPTION","group":"SPX.CBOE",
+				"expiration":1497626100000,"optionData":{"right":"CALL","strikePrice":2250}},
+			{"id":"SPX.CBOE.16M2017.P2200","ticker":"SPX","type":"OPTION","group":"SPX.CBOE",
+				"expiration":1497626100000,"optionData":{"right":"PUT","strikePrice":2200}},
+			{"id":"SPX.CBOE.21J2017.P2300","ticker":"SPX","type":"OPTION","group":"SPX.CBOE",
+				"expiration":1492808400000,"optionData":{"right":"PUT","strikePrice":2300}}
+	]`)
+
+	chain, err := NewClient("", "", "").OptionChain("SPX.CBOE")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "SPX.CBOE", chain.Group)
+	assert.Equal(t, 2, len(chain.Expirations), "Invalid expirations length")
+	// April
+	april := chain.Expirations[0]
+	assert.Equal(t, Timestamp{time.Unix(1492808400, 0)}, april.Expiration)
+	assert.Equal(t, 1, len(april.Strikes))
+	assert.Equal(t, 2300.0, april.Strikes[0].Strike)
+	assert.Equal(t, "SPX.CBOE.21J2017.C2300", april.Strikes[0].Call.ID)
+	assert.Equal(t, "SPX.CBOE.21J2017.P2300", april.Strikes[0].Put.ID)
+	// June
+	june := chain.Expirations[1]
+	assert.Equal(t, Timestamp{time.Unix(1497626100, 0)}, june.Expiration)
+	assert.Equal(t, 3, len(june.Strikes))
+	assert.Equal(t, 2200.0, june.Strikes[0].Strike)
+	assert.Nil(t, june.Strikes[0].Call)
+	assert.Equal(t, "SPX.CBOE.16M2017.P2200", june.Strikes[0].Put.ID)
+	assert.Equal(t, 2250.0, june.Strikes[1].Strike)
+	assert.Equal(t, "SPX.CBOE.16M2017.C2250", june.Strikes[1].Call.ID)
+	assert.Equal(t, "SPX.CBOE.16M2017.P2250", june.Strikes[1].Put.ID)
+	assert.Equal(t, 2300.0, june.Strikes[2].Strike)
+	assert.Equal(t, "SPX.CBOE.16M2017.C2300", june.Strikes[2].Call.ID)
+	assert.Nil(t, june.Strikes[2].Put)
+}
+
+func TestOptionChainLookups(t *testing.T) {
+	var symbols []Symbol
+	for _, spec := range []struct {
+		expiration int64
+		strike     float64
+	}{
+		{1492808400, 2250}, {1492808400, 2300},
+		{1497626100, 2200}, {1497626100, 2250}, {1497626100, 2300}, {1497626100, 2350},
+	} {
+		for _, right := range []OptionRight{OptionCall, OptionPut} {
+			s := Symbol{Expiration: Timestamp{time.Unix(spec.expiration, 0)}}
+			s.OptionData.Right = right
+			s.OptionData.StrikePrice = spec.strike
+			symbols = append(symbols, s)
+		}
+	}
+	symbols = append(symbols, Symbol{ID: "SPX.INDEX", Type: "INDEX"})
+	chain := NewOptionChain("SPX.CBOE", symbols)
+
+	exp, ok := chain.Nearest(time.Unix(1490000000, 0))
+	assert.True(t, ok)
+	assert.Equal(t, int64(1492808400), exp.Expiration.Unix())
+	exp, ok = chain.Nearest(time.Unix(1492808401, 0))
+	assert.True(t, ok)
+	assert.Equal(t, int64(1497626100), exp.Expiration.Unix())
+	_, ok = chain.Nearest(time.Unix(1497626101, 0))
+	assert.False(t, ok)
+
+	june, ok := chain.Expiration(time.Date(2017, time.June, 16, 0, 0, 0, 0, time.UTC))
+	assert.True(t, ok)
+	_, ok = chain.Expiration(time.Date(2017, time.June, 17, 0, 0, 0, 0, time.UTC))
+	assert.False(t, ok)
+
+	atm, ok := june.ATM(2262)
+	assert.True(t, ok)
+	assert.Equal(t, 2250.0, atm.Strike)
+	atm, _ = june.ATM(2275)
+	assert.Equal(t, 2250.0, atm.Strike)
+	atm, _ = june.ATM(5000)
+	assert.Equal(t, 2350.0, atm.Strike)
+
+	strike, ok := june.Strike(2300)
+	assert.True(t, ok)
+	assert.Equal(t, OptionCall, strike.Call.OptionData.Right)
+	assert.Equal(t, OptionPut, strike.Put.OptionData.Right)
+	_, ok = june.Strike(2275)
+	assert.False(t, ok)
+
+	strikes := func(s []OptionStrike) []float64 {
+		res := make([]float64, len(s))
+		for i := range s {
+			res[i] = s[i].Strike
+		}
+		return res
+	}
+	assert.Equal(t, []float64{2250, 2300}, strikes(june.StrikesAround(2262, 1)))
+	assert.Equal(t, []float64{2200, 2250, 2300, 2350}, strikes(june.StrikesAround(2262, 5)))
+	assert.Equal(t, []float64{2200}, strikes(june.StrikesAround(2100, 1)))
+	assert.Nil(t, june.StrikesAround(2262, 0))
+	assert.Nil(t, june.StrikesAround(2262, -1))
+	around := june.StrikesAround(2262, 1)
+	around[0].Strike = 0
+	assert.Equal(t, 2250.0, june.Strikes[1].Strike, "the result does not alias the chain")
+}
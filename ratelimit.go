@@ -0,0 +1,67 @@
+package exante
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// WithRateLimit limits the client to rps requests per second with bursts of
+// up to burst requests. Calls wait for their turn, or until the client
+// context is done. An rps that is not positive and finite disables limiting.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		if !(rps > 0) || math.IsInf(rps, 1) {
+			c.limiter = nil
+			return
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		c.limiter = &rateLimiter{
+			interval: time.Duration(float64(time.Second) / rps),
+			burst:    float64(burst),
+			tokens:   float64(burst),
+			last:     time.Now(),
+		}
+	}
+}
+
+// rateLimiter is a token bucket shared by all copies of a client.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration // time to earn one token
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	delay := time.Duration(-l.tokens * float64(l.interval))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Give back the reserved token
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
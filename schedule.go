@@ -0,0 +1,150 @@
+package exante
+
+import (
+	"sort"
+	"time"
+)
+
+// Schedule answers trading hours questions for a symbol. Intervals are
+// absolute timestamps, so sessions crossing midnight or a DST change need
+// no special handling; adjacent sessions are joined when looking for
+// opens and closes.
+type Schedule struct {
+	intervals []SymbolScheduleInterval // ordered by start
+	open      []period                 // merged trading periods
+}
+
+type period struct {
+	start, end time.Time
+}
+
+// Schedule fetches the schedule of a symbol.
+func (c *Client) Schedule(id string) (*Schedule, error) {
+	intervals, err := c.SymbolSchedule(id)
+	if err != nil {
+		return nil, err
+	}
+	return NewSchedule(intervals), nil
+}
+
+func NewSchedule(intervals []SymbolScheduleInterval) *Schedule {
+	s := &Schedule{intervals: append([]SymbolScheduleInterval(nil), intervals...)}
+	sort.SliceStable(s.intervals, func(i, j int) bool {
+		return s.intervals[i].Period.Start.Before(s.intervals[j].Period.Start.Time)
+	})
+	for _, in := range s.intervals {
+		if !in.Name.IsTrading() || !in.Period.Start.Before(in.Period.End.Time) {
+			continue
+		}
+		p := period{in.Period.Start.Time, in.Period.End.Time}
+		if n := len(s.open); n > 0 && !p.start.After(s.open[n-1].end) {
+			if p.end.After(s.open[n-1].end) {
+				s.open[n-1].end = p.end
+			}
+			continue
+		}
+		s.open = append(s.open, p)
+	}
+	return s
+}
+
+func (s *Schedule) Intervals() []SymbolScheduleInterval {
+	return s.intervals
+}
+
+// SessionAt returns the schedule interval containing t.
+func (s *Schedule) SessionAt(t time.Time) (SymbolScheduleInterval, bool) {
+	for _, in := range s.intervals {
+		if !t.Before(in.Period.Start.Time) && t.Before(in.Period.End.Time) {
+			return in, true
+		}
+	}
+	return SymbolScheduleInterval{}, false
+}
+
+// IsOpen reports whether any trading session is running at t.
+func (s *Schedule) IsOpen(t time.Time) bool {
+	_, ok := s.openAt(t)
+	return ok
+}
+
+// NextOpen returns the first time after t at which trading starts.
+func (s *Schedule) NextOpen(t time.Time) (time.Time, bool) {
+	for _, p := range s.open {
+		if p.start.After(t) {
+			return p.start, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// NextClose returns the end of the trading period running at t or, when
+// closed, the end of the next one.
+func (s *Schedule) NextClose(t time.Time) (time.Time, bool) {
+	for _, p := range s.open {
+		if p.end.After(t) {
+			return p.end, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Covers reports whether t falls within the span of known intervals.
+// Answers outside of it only reflect missing data.
+func (s *Schedule) Covers(t time.Time) bool {
+	if len(s.intervals) == 0 {
+		return false
+	}
+	first := s.intervals[0].Period.Start
+	last := s.intervals[0].Period.End
+	for _, in := range s.intervals {
+		if in.Period.End.After(last.Time) {
+			last = in.Period.End
+		}
+	}
+	return !t.Before(first.Time) && t.Before(last.Time)
+}
+
+// Bars returns the start times of the bars of the given duration in
+// [from, to) overlapping a trading session, i.e. the bars the API may
+// return. Bars are aligned to multiples of the duration since the Unix
+// epoch. Periods not covered by the schedule yield no bars.
+func (s *Schedule) Bars(duration Duration, from, to time.Time) []time.Time {
+	secs := int64(duration)
+	if secs <= 0 {
+		return nil
+	}
+	align := func(t time.Time) time.Time {
+		ts := t.Unix()
+		return time.Unix(ts-((ts%secs)+secs)%secs, 0)
+	}
+	d := time.Duration(secs) * time.Second
+	var bars []time.Time
+	for _, p := range s.open {
+		bar := align(p.start)
+		if bar.Before(from) {
+			bar = align(from)
+			if bar.Before(from) {
+				bar = bar.Add(d)
+			}
+		}
+		for ; bar.Before(p.end) && bar.Before(to); bar = bar.Add(d) {
+			// Periods shorter than a bar may share it with the previous one.
+			if n := len(bars); n > 0 && !bar.After(bars[n-1]) {
+				continue
+			}
+			bars = append(bars, bar)
+		}
+	}
+	return bars
+}
+
+func (s *Schedule) openAt(t time.Time) (period, bool) {
+	i := sort.Search(len(s.open), func(i int) bool {
+		return s.open[i].end.After(t)
+	})
+	if i < len(s.open) && !t.Before(s.open[i].start) {
+		return s.open[i], true
+	}
+	return period{}, false
+}
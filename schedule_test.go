@@ -0,0 +1,130 @@
+package exante
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func interval(name SessionName, start, end time.Time) SymbolScheduleInterval {
+	var in SymbolScheduleInterval
+	in.Name = name
+	in.Period.Start = Timestamp{start}
+	in.Period.End = Timestamp{end}
+	return in
+}
+
+func ms(v int64) time.Time {
+	return time.Unix(v/1000, 0)
+}
+
+func TestSchedule(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseUrl).
+		Get("/symbols/AAPL.NASDAQ/schedule").
+		Reply(200).
+		BodyString(`{"intervals":[
+			{"name":"AfterMarket","period":{"start":1493064000000,"end":1493078400000}},
+			{"name":"Offline","period":{"start":1493078400000,"end":1493107200000}},
+			{"name":"PreMarket","period":{"start":1493020800000,"end":1493040600000}},
+			{"name":"MainSession","period":{"start":1493040600000,"end":1493064000000}},
+			{"name":"PreMarket","period":{"start":1493107200000,"end":1493127000000}}
+	]}`)
+
+	schedule, err := NewClient("", "", "").Schedule("AAPL.NASDAQ")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 5, len(schedule.Intervals()))
+	assert.Equal(t, SessionPreMarket, schedule.Intervals()[0].Name)
+
+	session, ok := schedule.SessionAt(ms(1493040600000))
+	assert.True(t, ok)
+	assert.Equal(t, SessionMain, session.Name)
+	session, ok = schedule.SessionAt(ms(1493078400000))
+	assert.True(t, ok)
+	assert.Equal(t, SessionOffline, session.Name)
+	_, ok = schedule.SessionAt(ms(1493000000000))
+	assert.False(t, ok)
+
+	assert.True(t, schedule.IsOpen(ms(1493020800000)))
+	assert.True(t, schedule.IsOpen(ms(1493070000000)))
+	assert.False(t, schedule.IsOpen(ms(1493078400000)))
+	assert.False(t, schedule.IsOpen(ms(1493000000000)))
+
+	// Pre, main and after market form a single trading period
+	next, ok := schedule.NextClose(ms(1493030000000))
+	assert.True(t, ok)
+	assert.Equal(t, ms(1493078400000), next)
+	next, ok = schedule.NextOpen(ms(1493030000000))
+	assert.True(t, ok)
+	assert.Equal(t, ms(1493107200000), next)
+	next, ok = schedule.NextClose(ms(1493090000000))
+	assert.True(t, ok)
+	assert.Equal(t, ms(1493127000000), next)
+	_, ok = schedule.NextOpen(ms(1493107200000))
+	assert.False(t, ok)
+
+	assert.True(t, schedule.Covers(ms(1493100000000)))
+	assert.False(t, schedule.Covers(ms(1493127000000)))
+}
+
+func TestScheduleMidnightAndDST(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip(err)
+	}
+	// Clocks go forward at 01:00 on 26 March 2017, the session is split
+	// at midnight by the API.
+	schedule := NewSchedule([]SymbolScheduleInterval{
+		interval("MainSession", time.Date(2017, 3, 25, 22, 0, 0, 0, london), time.Date(2017, 3, 26, 0, 0, 0, 0, london)),
+		interval("MainSession", time.Date(2017, 3, 26, 0, 0, 0, 0, london), time.Date(2017, 3, 26, 3, 0, 0, 0, london)),
+		interval("Offline", time.Date(2017, 3, 26, 3, 0, 0, 0, london), time.Date(2017, 3, 26, 22, 0, 0, 0, london)),
+	})
+
+	assert.True(t, schedule.IsOpen(time.Date(2017, 3, 26, 0, 30, 0, 0, london)))
+	assert.True(t, schedule.IsOpen(time.Date(2017, 3, 26, 2, 30, 0, 0, london)))
+	assert.False(t, schedule.IsOpen(time.Date(2017, 3, 26, 3, 0, 0, 0, london)))
+
+	end, ok := schedule.NextClose(time.Date(2017, 3, 25, 23, 0, 0, 0, london))
+	assert.True(t, ok)
+	assert.True(t, end.Equal(time.Date(2017, 3, 26, 3, 0, 0, 0, london)))
+	// Only four hours of trading because of the lost hour
+	assert.Equal(t, 4*time.Hour, end.Sub(time.Date(2017, 3, 25, 22, 0, 0, 0, london)))
+	_, ok = schedule.NextOpen(time.Date(2017, 3, 25, 23, 0, 0, 0, london))
+	assert.False(t, ok)
+}
+
+func TestScheduleBars(t *testing.T) {
+	day := func(d int, hour, min int) time.Time {
+		return time.Date(2024, 1, d, hour, min, 0, 0, time.UTC)
+	}
+	s := NewSchedule([]SymbolScheduleInterval{
+		interval(SessionPreMarket, day(5, 9, 0), day(5, 14, 30)),
+		interval(SessionMain, day(5, 14, 30), day(5, 21, 0)),
+		interval(SessionClosed, day(5, 21, 0), day(8, 14, 30)),
+		interval(SessionMain, day(8, 14, 30), day(8, 21, 0)),
+	})
+
+	bars := s.Bars(Duration1Day, day(1, 0, 0), day(10, 0, 0))
+	assert.Equal(t, []time.Time{day(5, 0, 0), day(8, 0, 0)}, utc(bars))
+
+	bars = s.Bars(Duration1Hour, day(5, 19, 30), day(8, 16, 0))
+	assert.Equal(t, []time.Time{day(5, 20, 0), day(8, 14, 0), day(8, 15, 0)}, utc(bars))
+
+	assert.Len(t, s.Bars(Duration15Minutes, day(1, 0, 0), day(10, 0, 0)), 12*4+26)
+	assert.Empty(t, s.Bars(Duration1Day, day(6, 0, 0), day(8, 0, 0)))
+}
+
+func utc(times []time.Time) []time.Time {
+	res := make([]time.Time, len(times))
+	for i, t := range times {
+		res[i] = t.UTC()
+	}
+	return res
+}
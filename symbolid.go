@@ -0,0 +1,126 @@
+package exante
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SymbolID is the structured form of an Exante symbol ID such as
+// "AAPL.NASDAQ", "6R.CME.M2018" or "SPX.CBOE.16M2017.P2250".
+type SymbolID struct {
+	Ticker   string
+	Exchange string
+
+	// Contract month and year of futures and options, zero otherwise
+	Month time.Month
+	Year  int
+	// Expiration day of options, zero when not part of the ID
+	Day int
+
+	// Empty for futures and other non-options
+	Right  OptionRight
+	Strike float64
+}
+
+const monthCodes = "FGHJKMNQUVXZ"
+
+var (
+	symbolIDRe = regexp.MustCompile(`^(.+)\.([^.]+)$`)
+	contractRe = regexp.MustCompile(`^(\d{1,2})?([` + monthCodes + `])(\d{4})$`)
+	optionRe   = regexp.MustCompile(`^(.+)\.([^.]+)\.(\d{1,2})?([` + monthCodes + `])(\d{4})\.([PC])(\d+(?:\.\d+)?)$`)
+)
+
+// ParseSymbolID splits an Exante symbol ID into its parts.
+func ParseSymbolID(s string) (SymbolID, error) {
+	if m := optionRe.FindStringSubmatch(s); m != nil {
+		id := SymbolID{Ticker: m[1], Exchange: m[2]}
+		if err := id.setContract(m[3], m[4], m[5]); err != nil {
+			return SymbolID{}, fmt.Errorf("invalid symbol ID %q: %v", s, err)
+		}
+		id.Right = OptionCall
+		if m[6] == "P" {
+			id.Right = OptionPut
+		}
+		id.Strike, _ = strconv.ParseFloat(m[7], 64)
+		return id, nil
+	}
+	m := symbolIDRe.FindStringSubmatch(s)
+	if m == nil {
+		return SymbolID{}, fmt.Errorf("invalid symbol ID %q", s)
+	}
+	// Futures have the contract as the last part: TICKER.EXCHANGE.M2018
+	if c := contractRe.FindStringSubmatch(m[2]); c != nil && c[1] == "" {
+		if base := symbolIDRe.FindStringSubmatch(m[1]); base != nil {
+			id := SymbolID{Ticker: base[1], Exchange: base[2]}
+			if err := id.setContract("", c[2], c[3]); err != nil {
+				return SymbolID{}, fmt.Errorf("invalid symbol ID %q: %v", s, err)
+			}
+			return id, nil
+		}
+	}
+	return SymbolID{Ticker: m[1], Exchange: m[2]}, nil
+}
+
+func (id *SymbolID) setContract(day, month, year string) error {
+	id.Month = time.Month(strings.IndexByte(monthCodes, month[0]) + 1)
+	id.Year, _ = strconv.Atoi(year)
+	if day != "" {
+		id.Day, _ = strconv.Atoi(day)
+		if id.Day < 1 || id.Day > 31 {
+			return fmt.Errorf("invalid expiration day %s", day)
+		}
+	}
+	return nil
+}
+
+func (id SymbolID) IsFuture() bool {
+	return id.Month != 0 && id.Right == ""
+}
+
+func (id SymbolID) IsOption() bool {
+	return id.Right != ""
+}
+
+// Contract returns the month/year part of the ID, e.g. "M2018" or
+// "16M2017", or an empty string for non-derivatives.
+func (id SymbolID) Contract() string {
+	code, ok := MonthCode(id.Month)
+	if !ok {
+		return ""
+	}
+	var b strings.Builder
+	if id.Day > 0 {
+		b.WriteString(strconv.Itoa(id.Day))
+	}
+	b.WriteByte(code)
+	b.WriteString(strconv.Itoa(id.Year))
+	return b.String()
+}
+
+// String builds the Exante symbol ID.
+func (id SymbolID) String() string {
+	parts := []string{id.Ticker, id.Exchange}
+	if c := id.Contract(); c != "" {
+		parts = append(parts, c)
+	}
+	if id.IsOption() {
+		right := "C"
+		if id.Right == OptionPut {
+			right = "P"
+		}
+		parts = append(parts, right+strconv.FormatFloat(id.Strike, 'f', -1, 64))
+	}
+	return strings.Join(parts, ".")
+}
+
+// MonthCode returns the futures month code letter, e.g. 'M' for June, and
+// false for months outside January to December.
+func MonthCode(m time.Month) (byte, bool) {
+	if m < time.January || m > time.December {
+		return 0, false
+	}
+	return monthCodes[m-1], true
+}
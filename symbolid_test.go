@@ -0,0 +1,82 @@
+package exante
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSymbolID(t *testing.T) {
+	tests := []struct {
+		id       string
+		expected SymbolID
+	}{
+		{"AAPL.NASDAQ", SymbolID{Ticker: "AAPL", Exchange: "NASDAQ"}},
+		{"LEN.B.NYSE", SymbolID{Ticker: "LEN.B", Exchange: "NYSE"}},
+		{"USD/RUB.EXANTE", SymbolID{Ticker: "USD/RUB", Exchange: "EXANTE"}},
+		{"6R.CME.M2018", SymbolID{Ticker: "6R", Exchange: "CME", Month: time.June, Year: 2018}},
+		{"6R.CME.K2017", SymbolID{Ticker: "6R", Exchange: "CME", Month: time.May, Year: 2017}},
+		{"SPX.CBOE.16M2017.P2250", SymbolID{Ticker: "SPX", Exchange: "CBOE",
+			Month: time.June, Year: 2017, Day: 16, Right: "PUT", Strike: 2250}},
+		{"MA.CBOE.15U2017.P140", SymbolID{Ticker: "MA", Exchange: "CBOE",
+			Month: time.September, Year: 2017, Day: 15, Right: "PUT", Strike: 140}},
+		{"ES.CME.H2018.C2712.5", SymbolID{Ticker: "ES", Exchange: "CME",
+			Month: time.March, Year: 2018, Right: "CALL", Strike: 2712.5}},
+	}
+
+	for _, tt := range tests {
+		id, err := ParseSymbolID(tt.id)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		assert.Equal(t, tt.expected, id, tt.id)
+		assert.Equal(t, tt.id, id.String())
+	}
+}
+
+func TestParseSymbolIDKinds(t *testing.T) {
+	future, _ := ParseSymbolID("6R.CME.M2018")
+	assert.True(t, future.IsFuture())
+	assert.False(t, future.IsOption())
+	assert.Equal(t, "M2018", future.Contract())
+
+	option, _ := ParseSymbolID("SPX.CBOE.16M2017.P2250")
+	assert.False(t, option.IsFuture())
+	assert.True(t, option.IsOption())
+	assert.Equal(t, "16M2017", option.Contract())
+
+	stock, _ := ParseSymbolID("AAPL.NASDAQ")
+	assert.False(t, stock.IsFuture())
+	assert.False(t, stock.IsOption())
+	assert.Equal(t, "", stock.Contract())
+}
+
+func TestParseSymbolIDInvalid(t *testing.T) {
+	for _, id := range []string{"", "AAPL", ".NASDAQ", "SPX.CBOE.40M2017.P2250"} {
+		_, err := ParseSymbolID(id)
+		assert.Error(t, err, id)
+	}
+}
+
+func TestSymbolIDBuilder(t *testing.T) {
+	assert.Equal(t, "6R.CME.Z2018",
+		SymbolID{Ticker: "6R", Exchange: "CME", Month: time.December, Year: 2018}.String())
+	assert.Equal(t, "SPX.CBOE.15U2017.C2300",
+		SymbolID{Ticker: "SPX", Exchange: "CBOE", Month: time.September, Year: 2017, Day: 15,
+			Right: "CALL", Strike: 2300}.String())
+}
+
+func TestMonthCode(t *testing.T) {
+	code, ok := MonthCode(time.January)
+	assert.True(t, ok)
+	assert.Equal(t, byte('F'), code)
+	code, ok = MonthCode(time.December)
+	assert.True(t, ok)
+	assert.Equal(t, byte('Z'), code)
+	for _, m := range []time.Month{0, 13, -1} {
+		_, ok := MonthCode(m)
+		assert.False(t, ok, m)
+	}
+}
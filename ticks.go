@@ -0,0 +1,76 @@
+package exante
+
+import (
+	"errors"
+	"math"
+)
+
+var errInvalidMPI = errors.New("minimum price increment must be positive")
+
+// RoundPrice rounds price to a multiple of the minimum price increment
+// mpi. The arithmetic is done on the shortest decimal representation of
+// both values, so RoundPrice(0.015125, 5e-06, RoundDown) returns 0.015125
+// rather than one tick less because of binary floating-point error.
+func RoundPrice(price, mpi float64, mode RoundingMode) (float64, error) {
+	p, tick, err := priceGrid(price, mpi)
+	if err != nil {
+		return 0, err
+	}
+	rounded, err := p.RoundToTick(tick, mode)
+	if err != nil {
+		return 0, err
+	}
+	return rounded.Float64(), nil
+}
+
+// IsOnGrid reports whether price is a multiple of mpi.
+func IsOnGrid(price, mpi float64) bool {
+	p, tick, err := priceGrid(price, mpi)
+	if err != nil {
+		return false
+	}
+	rounded, err := p.RoundToTick(tick, RoundDown)
+	return err == nil && rounded.Equal(p)
+}
+
+// TicksBetween returns the number of increments of mpi from one price to
+// another, negative when to is below from. Prices off the grid are counted
+// to the nearest tick.
+func TicksBetween(from, to, mpi float64) (int64, error) {
+	a, tick, err := priceGrid(from, mpi)
+	if err != nil {
+		return 0, err
+	}
+	b, _, err := priceGrid(to, mpi)
+	if err != nil {
+		return 0, err
+	}
+	n, err := b.Sub(a).QuoInt(tick, RoundNearest)
+	if err != nil {
+		return 0, err
+	}
+	if !n.IsInt64() {
+		return 0, errors.New("tick count overflows int64")
+	}
+	return n.Int64(), nil
+}
+
+// RoundPrice rounds price to the minimum price increment of the symbol.
+func (s *Symbol) RoundPrice(price float64, mode RoundingMode) (float64, error) {
+	return RoundPrice(price, s.MPI, mode)
+}
+
+// IsValidPrice reports whether price is on the grid of the symbol.
+func (s *Symbol) IsValidPrice(price float64) bool {
+	return IsOnGrid(price, s.MPI)
+}
+
+func priceGrid(price, mpi float64) (Decimal, Decimal, error) {
+	if math.IsNaN(mpi) || math.IsInf(mpi, 0) || mpi <= 0 {
+		return Decimal{}, Decimal{}, errInvalidMPI
+	}
+	if math.IsNaN(price) || math.IsInf(price, 0) {
+		return Decimal{}, Decimal{}, errors.New("price must be finite")
+	}
+	return DecimalFromFloat(price), DecimalFromFloat(mpi), nil
+}
@@ -0,0 +1,147 @@
+package exante
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testIncrements = []float64{5e-06, 0.0001, 0.001, 0.005, 0.01, 0.05, 0.25, 0.5, 1, 5, 0.03125}
+
+// gridCase is a random price around a random increment for property tests.
+type gridCase struct {
+	MPI   float64
+	Ticks int64
+	Price float64
+}
+
+func (gridCase) Generate(r *rand.Rand, size int) reflect.Value {
+	mpi := testIncrements[r.Intn(len(testIncrements))]
+	ticks := r.Int63n(10000000) - 5000000
+	onGrid := DecimalFromFloat(mpi).Mul(NewDecimal(ticks, 0)).Float64()
+	// Anywhere between this tick and the next one
+	price := onGrid + r.Float64()*mpi
+	price, _ = strconv.ParseFloat(strconv.FormatFloat(price, 'g', 12, 64), 64)
+	return reflect.ValueOf(gridCase{MPI: mpi, Ticks: ticks, Price: price})
+}
+
+func (c gridCase) onGrid() float64 {
+	return DecimalFromFloat(c.MPI).Mul(NewDecimal(c.Ticks, 0)).Float64()
+}
+
+func TestRoundPrice(t *testing.T) {
+	tests := []struct {
+		price, mpi        float64
+		down, up, nearest float64
+	}{
+		{0.0151237, 5e-06, 0.01512, 0.015125, 0.015125},
+		{0.015125, 5e-06, 0.015125, 0.015125, 0.015125},
+		{143.635, 0.01, 143.63, 143.64, 143.64},
+		{143.634, 0.01, 143.63, 143.64, 143.63},
+		{0.3, 0.1, 0.3, 0.3, 0.3},
+		{-1.005, 0.01, -1.01, -1, -1.01},
+		{2262, 5, 2260, 2265, 2260},
+	}
+	for _, tt := range tests {
+		down, err := RoundPrice(tt.price, tt.mpi, RoundDown)
+		assert.NoError(t, err)
+		up, _ := RoundPrice(tt.price, tt.mpi, RoundUp)
+		nearest, _ := RoundPrice(tt.price, tt.mpi, RoundNearest)
+		assert.Equal(t, tt.down, down, "down %v/%v", tt.price, tt.mpi)
+		assert.Equal(t, tt.up, up, "up %v/%v", tt.price, tt.mpi)
+		assert.Equal(t, tt.nearest, nearest, "nearest %v/%v", tt.price, tt.mpi)
+	}
+
+	for _, mpi := range []float64{0, -0.01, math.NaN(), math.Inf(1)} {
+		_, err := RoundPrice(1, mpi, RoundDown)
+		assert.Error(t, err)
+	}
+	_, err := RoundPrice(math.NaN(), 0.01, RoundDown)
+	assert.Error(t, err)
+
+	symbol := Symbol{MPI: 0.25}
+	price, err := symbol.RoundPrice(10.1, RoundUp)
+	assert.NoError(t, err)
+	assert.Equal(t, 10.25, price)
+	assert.True(t, symbol.IsValidPrice(10.75))
+	assert.False(t, symbol.IsValidPrice(10.7))
+}
+
+func TestIsOnGrid(t *testing.T) {
+	assert.True(t, IsOnGrid(0.3, 0.1))
+	assert.True(t, IsOnGrid(143.635, 0.005))
+	assert.False(t, IsOnGrid(143.635, 0.01))
+	assert.True(t, IsOnGrid(0.000015, 5e-06))
+	assert.False(t, IsOnGrid(1, 0))
+}
+
+func TestTicksBetween(t *testing.T) {
+	n, err := TicksBetween(143.315, 144.15, 0.005)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(167), n)
+	n, _ = TicksBetween(144.15, 143.315, 0.005)
+	assert.Equal(t, int64(-167), n)
+	n, _ = TicksBetween(0.1, 0.3, 0.1)
+	assert.Equal(t, int64(2), n)
+	_, err = TicksBetween(1, 2, 0)
+	assert.Error(t, err)
+}
+
+func TestRoundPriceProperties(t *testing.T) {
+	config := &quick.Config{MaxCount: 5000}
+
+	// Grid prices are left untouched by every mode
+	onGrid := func(c gridCase) bool {
+		p := c.onGrid()
+		for _, mode := range []RoundingMode{RoundDown, RoundUp, RoundNearest} {
+			if r, err := RoundPrice(p, c.MPI, mode); err != nil || r != p {
+				return false
+			}
+		}
+		return IsOnGrid(p, c.MPI)
+	}
+	if err := quick.Check(onGrid, config); err != nil {
+		t.Error(err)
+	}
+
+	// Rounded prices are on the grid, bracket the price within one tick
+	// and nearest picks one of the brackets
+	bracket := func(c gridCase) bool {
+		down, err1 := RoundPrice(c.Price, c.MPI, RoundDown)
+		up, err2 := RoundPrice(c.Price, c.MPI, RoundUp)
+		nearest, err3 := RoundPrice(c.Price, c.MPI, RoundNearest)
+		if err1 != nil || err2 != nil || err3 != nil {
+			return false
+		}
+		if !IsOnGrid(down, c.MPI) || !IsOnGrid(up, c.MPI) || !IsOnGrid(nearest, c.MPI) {
+			return false
+		}
+		if down > c.Price || up < c.Price || (nearest != down && nearest != up) {
+			return false
+		}
+		n, err := TicksBetween(down, up, c.MPI)
+		if err != nil || n < 0 || n > 1 {
+			return false
+		}
+		return (n == 0) == IsOnGrid(c.Price, c.MPI)
+	}
+	if err := quick.Check(bracket, config); err != nil {
+		t.Error(err)
+	}
+
+	// Tick counts are consistent with grid arithmetic
+	distance := func(c gridCase, k int16) bool {
+		from := c.onGrid()
+		to := DecimalFromFloat(from).Add(DecimalFromFloat(c.MPI).Mul(NewDecimal(int64(k), 0))).Float64()
+		n, err := TicksBetween(from, to, c.MPI)
+		return err == nil && n == int64(k)
+	}
+	if err := quick.Check(distance, config); err != nil {
+		t.Error(err)
+	}
+}
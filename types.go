@@ -0,0 +1,79 @@
+package exante
+
+// SymbolType is the instrument type of a symbol. Values unknown to this
+// package are kept as is.
+type SymbolType string
+
+const (
+	SymbolTypeStock          SymbolType = "STOCK"
+	SymbolTypeBond           SymbolType = "BOND"
+	SymbolTypeFund           SymbolType = "FUND"
+	SymbolTypeCurrency       SymbolType = "CURRENCY"
+	SymbolTypeFXSpot         SymbolType = "FX_SPOT"
+	SymbolTypeFuture         SymbolType = "FUTURE"
+	SymbolTypeOption         SymbolType = "OPTION"
+	SymbolTypeCalendarSpread SymbolType = "CALENDAR_SPREAD"
+)
+
+// IsKnown reports whether t is one of the SymbolType constants.
+func (t SymbolType) IsKnown() bool {
+	switch t {
+	case SymbolTypeStock, SymbolTypeBond, SymbolTypeFund, SymbolTypeCurrency,
+		SymbolTypeFXSpot, SymbolTypeFuture, SymbolTypeOption, SymbolTypeCalendarSpread:
+		return true
+	}
+	return false
+}
+
+// IsDerivative reports whether instruments of this type expire.
+func (t SymbolType) IsDerivative() bool {
+	return t == SymbolTypeFuture || t == SymbolTypeOption || t == SymbolTypeCalendarSpread
+}
+
+func (t SymbolType) String() string {
+	return string(t)
+}
+
+type OptionRight string
+
+const (
+	OptionPut  OptionRight = "PUT"
+	OptionCall OptionRight = "CALL"
+)
+
+func (r OptionRight) IsKnown() bool {
+	return r == OptionPut || r == OptionCall
+}
+
+func (r OptionRight) String() string {
+	return string(r)
+}
+
+// SessionName is the name of a schedule interval.
+type SessionName string
+
+const (
+	SessionPreMarket   SessionName = "PreMarket"
+	SessionMain        SessionName = "MainSession"
+	SessionAfterMarket SessionName = "AfterMarket"
+	SessionOffline     SessionName = "Offline"
+	SessionClosed      SessionName = "Closed"
+)
+
+func (n SessionName) IsKnown() bool {
+	switch n {
+	case SessionPreMarket, SessionMain, SessionAfterMarket, SessionOffline, SessionClosed:
+		return true
+	}
+	return false
+}
+
+// IsTrading reports whether trading happens during the session. Unknown
+// session names are assumed to be trading ones.
+func (n SessionName) IsTrading() bool {
+	return n != SessionOffline && n != SessionClosed
+}
+
+func (n SessionName) String() string {
+	return string(n)
+}
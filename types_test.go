@@ -0,0 +1,61 @@
+package exante
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSymbolType(t *testing.T) {
+	assert.True(t, SymbolTypeFuture.IsDerivative())
+	assert.True(t, SymbolTypeOption.IsDerivative())
+	assert.True(t, SymbolTypeCalendarSpread.IsDerivative())
+	assert.False(t, SymbolTypeStock.IsDerivative())
+	assert.False(t, SymbolTypeCurrency.IsDerivative())
+
+	assert.True(t, SymbolTypeFXSpot.IsKnown())
+	assert.False(t, SymbolType("CFD").IsKnown())
+	assert.False(t, SymbolType("stock").IsKnown())
+	assert.Equal(t, "BOND", SymbolTypeBond.String())
+}
+
+func TestOptionRight(t *testing.T) {
+	assert.True(t, OptionPut.IsKnown())
+	assert.True(t, OptionCall.IsKnown())
+	assert.False(t, OptionRight("").IsKnown())
+	assert.Equal(t, "CALL", OptionCall.String())
+}
+
+func TestSessionName(t *testing.T) {
+	assert.True(t, SessionMain.IsTrading())
+	assert.True(t, SessionPreMarket.IsTrading())
+	assert.True(t, SessionName("Auction").IsTrading())
+	assert.False(t, SessionOffline.IsTrading())
+	assert.False(t, SessionClosed.IsTrading())
+	assert.False(t, SessionName("Auction").IsKnown())
+}
+
+func TestTypedEnumsJSON(t *testing.T) {
+	var symbol Symbol
+	err := json.Unmarshal([]byte(`{"type":"CFD","optionData":{"right":"PUT"}}`), &symbol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, SymbolType("CFD"), symbol.Type)
+	assert.Equal(t, OptionPut, symbol.OptionData.Right)
+
+	var interval SymbolScheduleInterval
+	err = json.Unmarshal([]byte(`{"name":"Auction","period":{"start":0,"end":0}}`), &interval)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, SessionName("Auction"), interval.Name)
+
+	b, err := json.Marshal(symbol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(b), `"Type":"CFD"`)
+	assert.Contains(t, string(b), `"Right":"PUT"`)
+}